@@ -2,46 +2,214 @@ package main
 
 import (
 	"context"
-	"log"
+	"errors"
+	"log/slog"
+	"net/http"
+	"os"
+	"os/signal"
+	"syscall"
 	"time"
 
 	"github.com/cina_dex_backend/internal/config"
+	"github.com/cina_dex_backend/internal/health"
 	apihttp "github.com/cina_dex_backend/internal/http"
+	"github.com/cina_dex_backend/internal/lifecycle"
+	"github.com/cina_dex_backend/internal/logging"
+	"github.com/cina_dex_backend/internal/metrics"
 	"github.com/cina_dex_backend/internal/onchain"
 	"github.com/cina_dex_backend/internal/service"
+	"github.com/cina_dex_backend/internal/store"
+	"github.com/cina_dex_backend/internal/tracing"
 )
 
 func main() {
 	cfg, err := config.Load()
 	if err != nil {
-		log.Fatalf("load config: %v", err)
+		logging.Fatal("load config", err)
 	}
+	// JSON or text logs at LOG_LEVEL; request logs carry request_id and trace_id.
+	logging.Init(cfg.Log)
 
-	ctx := context.Background()
+	// SIGINT / SIGTERM start a graceful shutdown; a second signal kills.
+	signalCtx, stopSignals := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stopSignals()
 
-	chainClient, err := onchain.NewEthClient(ctx, cfg)
+	// workers run until the HTTP server drained, so in-flight requests can
+	// still read the caches they maintain.
+	ctx, stopWorkers := context.WithCancel(context.Background())
+	defer stopWorkers()
+	workers := lifecycle.NewWorkers()
+
+	// spans from HTTP handlers through services to RPC calls; see TRACING_EXPORTER.
+	shutdownTracing, err := tracing.Init(ctx, cfg.Tracing)
+	if err != nil {
+		logging.Fatal("init tracing", err)
+	}
+	defer shutdownTracing(context.Background())
+
+	ethClient, err := onchain.NewEthClient(ctx, cfg)
+	if err != nil {
+		logging.Fatal("init on-chain client", err)
+	}
+	defer ethClient.Close()
+	// per-user and per-loan reads are cached for RPC_CACHE_TTL or until the
+	// next block, and concurrent identical reads share one RPC call.
+	var chainClient onchain.Client = ethClient
+	var rpcCache *onchain.CachedClient
+	if cfg.RPCCache.TTL > 0 {
+		rpcCache = onchain.NewCachedClient(ethClient, cfg.RPCCache.TTL, cfg.RPCCache.Size)
+		chainClient = rpcCache
+		metrics.RegisterRPCCache(rpcCache.Stats)
+	}
+
+	// cache holds periodically refreshed pool state and price; entries older
+	// than STATE_CACHE_MAX_AGE are bypassed in favour of live reads.
+	stateCache := service.NewStateCache(cfg.Refresh.CacheMaxAge)
+	priceSvc := service.NewPriceService(cfg, service.NewPriceSources(cfg, chainClient))
+	priceHistorySvc, err := service.NewPriceHistoryService(cfg)
 	if err != nil {
-		log.Fatalf("init on-chain client: %v", err)
+		logging.Fatal("init price history", err)
 	}
+	// record every refreshed price for OHLC queries.
+	service.StartPriceRecorder(ctx, workers, stateCache, priceHistorySvc)
 
-	// cache holds periodically refreshed pool state and price.
-	stateCache := service.NewStateCache()
-	// start background job: refresh every 3 minutes.
-	service.StartStateUpdater(ctx, chainClient, stateCache, 3*time.Minute)
+	// push cache updates and watched loan health to SSE / WebSocket clients.
+	streamHub := service.NewStreamHub(cfg.Stream.MaxWatchedLoans)
+	service.StartStreamPublisher(ctx, workers, streamHub, stateCache, chainClient)
 
-	poolSvc := service.NewPoolService(chainClient, stateCache)
+	// start background job: refresh on new blocks or every STATE_REFRESH_MAX_INTERVAL.
+	service.StartStateUpdater(ctx, workers, chainClient, priceSvc, stateCache, cfg.Refresh)
+
+	poolMetricsSvc, err := service.NewPoolMetricsService(cfg, chainClient)
+	if err != nil {
+		logging.Fatal("init pool metrics", err)
+	}
+	// snapshot pool state periodically for TVL / utilization charts.
+	service.StartPoolSnapshotter(ctx, workers, poolMetricsSvc, cfg.PoolSnapshotInterval)
+
+	// index LendingPool / MockUSDT logs for per-user history. Without ABI
+	// files to verify the event signatures against, the indexer and the
+	// routes built on it stay off.
+	eventIndexer, err := service.NewEventIndexer(cfg, chainClient)
+	if errors.Is(err, onchain.ErrABINotFound) {
+		slog.Error("event indexer disabled: cannot verify event signatures; history, lender report, revenue and event exports are unavailable", "err", err)
+	} else if err != nil {
+		logging.Fatal("init event indexer", err)
+	}
+	service.StartEventIndexer(ctx, workers, eventIndexer, cfg.Events.PollInterval)
+
+	loanScanner := service.NewLoanScanner(chainClient)
+	poolSvc := service.NewPoolService(chainClient, stateCache, poolMetricsSvc, loanScanner, eventIndexer)
 	loanSvc := service.NewLoanService(chainClient)
-	quoteSvc := service.NewQuoteService(chainClient, stateCache)
+	activitySvc := service.NewActivityService(eventIndexer)
+	exportSvc := service.NewExportService(eventIndexer, loanScanner, priceHistorySvc)
+	revenueSvc := service.NewRevenueService(eventIndexer, loanScanner, priceHistorySvc, stateCache)
+	// revalue active loans and alert on bad debt.
+	solvencySvc := service.NewSolvencyService(cfg, loanScanner, poolSvc, priceSvc, stateCache, service.NewAlerter(cfg.Solvency.AlertWebhookURL))
+	service.StartSolvencyMonitor(ctx, workers, solvencySvc, stateCache, cfg.Solvency.Interval)
+	// notify webhook subscribers when loan LTVs cross their thresholds.
+	webhookSvc, err := service.NewWebhookService(cfg, chainClient, loanScanner, stateCache)
+	if err != nil {
+		logging.Fatal("init webhooks", err)
+	}
+	service.StartWebhooks(ctx, workers, webhookSvc, stateCache)
+	stressSvc := service.NewStressService(loanScanner, poolSvc, priceSvc, stateCache)
+	quoteSvc := service.NewQuoteService(cfg, chainClient, priceSvc, stateCache)
 	txSvc, err := service.NewTxService(cfg, chainClient)
 	if err != nil {
-		log.Fatalf("init tx service: %v", err)
+		logging.Fatal("init tx service", err)
 	}
 
-	r := apihttp.NewRouter(cfg, poolSvc, loanSvc, txSvc, quoteSvc)
+	var faucetSvc service.FaucetService
+	if cfg.Faucet.KeystorePath != "" {
+		faucetSigner, err := onchain.NewTransactor(ctx, ethClient, cfg.Faucet.KeystorePath, cfg.Faucet.KeystorePassword)
+		if err != nil {
+			logging.Fatal("init faucet signer", err)
+		}
+		grantLog, err := store.OpenAppendLog(cfg.Faucet.GrantsPath)
+		if err != nil {
+			logging.Fatal("open faucet grant log", err)
+		}
+		defer grantLog.Close()
+		faucetSvc, err = service.NewFaucetService(cfg, faucetSigner, grantLog)
+		if err != nil {
+			logging.Fatal("init faucet service", err)
+		}
+		slog.Info("faucet enabled", "signer", faucetSigner.From().Hex())
+	}
+
+	r, err := apihttp.NewRouter(cfg, apihttp.Services{
+		Pool:         poolSvc,
+		PoolMetrics:  poolMetricsSvc,
+		Revenue:      revenueSvc,
+		Solvency:     solvencySvc,
+		Stress:       stressSvc,
+		Webhooks:     webhookSvc,
+		Stream:       streamHub,
+		Loan:         loanSvc,
+		Events:       eventIndexer,
+		Activity:     activitySvc,
+		Export:       exportSvc,
+		Tx:           txSvc,
+		Quote:        quoteSvc,
+		PriceHistory: priceHistorySvc,
+		Cache:        stateCache,
+		RPCCache:     rpcCache,
+		Readiness:    service.NewReadinessService(cfg, chainClient, stateCache),
+		Faucet:       faucetSvc,
+	})
+	if err != nil {
+		logging.Fatal("init router", err)
+	}
+
+	srv := &http.Server{
+		Addr:              ":" + cfg.HTTPPort,
+		Handler:           r,
+		ReadHeaderTimeout: 10 * time.Second,
+	}
+	// Shutdown does not wait for hijacked WebSocket connections and would
+	// wait out long-lived SSE streams, so end both when it starts.
+	srv.RegisterOnShutdown(streamHub.Close)
+
+	serveErr := make(chan error, 1)
+	go func() {
+		slog.Info("starting API server", "addr", srv.Addr, "env", cfg.Env, "chain", cfg.ChainEnv)
+		serveErr <- srv.ListenAndServe()
+	}()
+
+	select {
+	case err := <-serveErr:
+		logging.Fatal("server stopped", err)
+	case <-signalCtx.Done():
+	}
+	stopSignals()
+	slog.Info("shutting down")
+	shutdown(srv, workers, stopWorkers, cfg.Shutdown)
+}
+
+// shutdown reports not ready, waits for load balancers to notice, drains
+// in-flight requests and then stops the background workers.
+func shutdown(srv *http.Server, workers *lifecycle.Workers, stopWorkers context.CancelFunc, cfg config.ShutdownConfig) {
+	health.SetShuttingDown()
+	time.Sleep(cfg.DrainDelay)
+
+	ctx, cancel := context.WithTimeout(context.Background(), cfg.Timeout)
+	defer cancel()
+	if err := srv.Shutdown(ctx); err != nil {
+		slog.Error("http shutdown", "err", err)
+	}
+
+	stopWorkers()
+	waitWorkers(workers, cfg.Timeout)
+}
 
-	addr := ":" + cfg.HTTPPort
-	log.Printf("starting API server on %s (env=%s, chain=%s)", addr, cfg.Env, cfg.ChainEnv)
-	if err := r.Run(addr); err != nil {
-		log.Fatalf("server stopped: %v", err)
+// waitWorkers waits up to timeout for every background worker to return.
+func waitWorkers(workers *lifecycle.Workers, timeout time.Duration) {
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+	if err := workers.Wait(ctx); err != nil {
+		slog.Error("stop workers", "err", err)
+		return
 	}
+	slog.Info("background workers stopped")
 }
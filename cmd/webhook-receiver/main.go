@@ -0,0 +1,74 @@
+// Command webhook-receiver is a local endpoint for testing loan health
+// webhooks: it verifies signatures and prints every notification.
+//
+//	go run ./cmd/webhook-receiver -secret <secret> [-addr :9000] [-fail 2]
+//
+// Register http://localhost:9000/ as the subscription URL; the API server
+// must run with WEBHOOKS_ALLOW_PRIVATE=true to deliver to it. -fail answers the
+// first N requests with 500 to exercise retries.
+package main
+
+import (
+	"crypto/hmac"
+	"flag"
+	"io"
+	"log"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync/atomic"
+	"time"
+
+	"github.com/cina_dex_backend/internal/service"
+)
+
+// maxSkew is how far the signed timestamp may be from the local clock.
+const maxSkew = 5 * time.Minute
+
+func main() {
+	addr := flag.String("addr", ":9000", "listen address")
+	secret := flag.String("secret", "", "subscription secret; empty skips signature checks")
+	fail := flag.Int64("fail", 0, "answer the first N requests with 500")
+	flag.Parse()
+
+	var received int64
+	http.HandleFunc("/", func(w http.ResponseWriter, r *http.Request) {
+		body, err := io.ReadAll(r.Body)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		n := atomic.AddInt64(&received, 1)
+
+		if *secret != "" {
+			ts, err := strconv.ParseInt(r.Header.Get("X-Webhook-Timestamp"), 10, 64)
+			if err != nil {
+				log.Printf("#%d rejected: missing timestamp", n)
+				http.Error(w, "missing timestamp", http.StatusUnauthorized)
+				return
+			}
+			if skew := time.Since(time.Unix(ts, 0)); skew > maxSkew || skew < -maxSkew {
+				log.Printf("#%d rejected: timestamp skew %s", n, skew)
+				http.Error(w, "stale timestamp", http.StatusUnauthorized)
+				return
+			}
+			want := service.WebhookSignature(*secret, ts, body)
+			got := strings.TrimPrefix(r.Header.Get("X-Webhook-Signature"), "sha256=")
+			if !hmac.Equal([]byte(got), []byte(want)) {
+				log.Printf("#%d rejected: bad signature", n)
+				http.Error(w, "bad signature", http.StatusUnauthorized)
+				return
+			}
+		}
+
+		log.Printf("#%d %s %s: %s", n, r.Header.Get("X-Webhook-Event"), r.Header.Get("X-Webhook-Id"), body)
+		if n <= *fail {
+			http.Error(w, "simulated failure", http.StatusInternalServerError)
+			return
+		}
+		w.WriteHeader(http.StatusNoContent)
+	})
+
+	log.Printf("webhook receiver listening on %s", *addr)
+	log.Fatal(http.ListenAndServe(*addr, nil))
+}
@@ -3,7 +3,13 @@ package config
 import (
 	"encoding/json"
 	"fmt"
+	"log/slog"
+	"net"
 	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
 )
 
 // ChainConfig holds on-chain addresses for a specific network.
@@ -16,6 +22,8 @@ type ChainConfig struct {
 	ChainlinkOracle string `json:"chainlinkOracle"`
 	FToken          string `json:"fToken"`
 	LendingPool     string `json:"lendingPool"`
+	// DeployBlock is the LendingPool deployment block, where event indexing starts.
+	DeployBlock uint64 `json:"deployBlock,omitempty"`
 }
 
 // Addresses represents the address book loaded from go_back/addresses.json.
@@ -24,6 +32,196 @@ type Addresses struct {
 	BSCMainnet ChainConfig `json:"bscMainnet"`
 }
 
+// FaucetConfig configures the server-side testnet faucet. The faucet is only
+// enabled when KeystorePath is set and the chain has a MockUSDT address.
+type FaucetConfig struct {
+	KeystorePath     string
+	KeystorePassword string
+	// USDTAmount is minted per grant, in smallest units (6 decimals).
+	USDTAmount string
+	// BNBAmount is dripped per grant in wei; "0" disables the BNB drip.
+	BNBAmount string
+	// Cooldown is the minimum time between two grants to the same address / IP.
+	Cooldown time.Duration
+	// Window is the period over which AddressLimit / IPLimit are counted.
+	Window       time.Duration
+	AddressLimit int
+	IPLimit      int
+	// GrantsPath is the JSON-lines file grants are recorded to.
+	GrantsPath string
+}
+
+// PriceConfig configures validation of the BNB/USD price.
+type PriceConfig struct {
+	// FeedHeartbeat is the max age of the feed's latest round before it is stale.
+	FeedHeartbeat time.Duration
+	// MaxDeviationBps is the max allowed gap between any source and the oracle
+	// price (the median price when the oracle is unavailable).
+	MaxDeviationBps int64
+	// MinSources is how many valid sources are needed for a price to be considered safe.
+	MinSources int
+
+	// DEX TWAP source; disabled when DexPool is empty.
+	// DexPool is a Uniswap/PancakeSwap V3 WBNB/USD-stable pool.
+	DexPool       string
+	DexTWAPWindow time.Duration
+	// DexBaseIsToken0 is true when WBNB is token0 of DexPool.
+	DexBaseIsToken0  bool
+	DexBaseDecimals  int64
+	DexQuoteDecimals int64
+
+	// HistoryRetention is how long sampled prices are kept for OHLC queries.
+	HistoryRetention time.Duration
+}
+
+// EventsConfig configures indexing of LendingPool / USDT logs.
+type EventsConfig struct {
+	// StartBlock is the first block to index; 0 falls back to
+	// ChainConfig.DeployBlock, then to the head block at first start.
+	StartBlock uint64
+	// ChunkSize is the max block range per eth_getLogs request.
+	ChunkSize uint64
+	// Confirmations keeps the indexer this many blocks behind head to avoid reorgs.
+	Confirmations uint64
+	// PollInterval is how often new blocks are indexed.
+	PollInterval time.Duration
+	// ABIDir holds LendingPool.json / MockUSDT.json, used to check the event
+	// signatures the indexer decodes.
+	ABIDir string
+}
+
+// SolvencyConfig configures the bad-debt / solvency monitor.
+type SolvencyConfig struct {
+	// Interval is how often all active loans are revalued.
+	Interval time.Duration
+	// CriticalShortfallBps is the bad debt / TotalAssets ratio at which the
+	// status turns critical; below it any bad debt is a warning.
+	CriticalShortfallBps int64
+	// AlertWebhookURL receives alerts as JSON POSTs; empty logs alerts only.
+	AlertWebhookURL string
+	// AlertRepeat re-sends an unchanged non-ok status after this long.
+	AlertRepeat time.Duration
+}
+
+// WebhooksConfig configures loan health webhook subscriptions.
+type WebhooksConfig struct {
+	// MaxSubscriptions caps the number of registered subscriptions.
+	MaxSubscriptions int
+	// MaxPerClient caps the subscriptions registered from one client IP.
+	MaxPerClient int
+	// MaxPerTarget caps the subscriptions watching one loan or borrower.
+	MaxPerTarget int
+	// MaxAttempts is how many times a notification is sent before giving up.
+	MaxAttempts int
+	// RetryBackoff is the delay before the first retry; it doubles per attempt.
+	RetryBackoff time.Duration
+	// Timeout bounds each delivery request.
+	Timeout time.Duration
+	// AllowPrivate permits webhook URLs on loopback / private networks, e.g.
+	// a local receiver during development. Off by default: POST /webhooks is
+	// public, so it would let anyone reach internal services.
+	AllowPrivate bool
+}
+
+// State refresh modes.
+const (
+	// RefreshInterval refreshes every MaxInterval only.
+	RefreshInterval = "interval"
+	// RefreshPoll polls eth_blockNumber and refreshes on new blocks.
+	RefreshPoll = "poll"
+	// RefreshSubscribe subscribes to new heads over WSURL, falling back to
+	// polling while the subscription is down.
+	RefreshSubscribe = "subscribe"
+)
+
+// StateRefreshConfig configures how often pool state and price are refreshed.
+type StateRefreshConfig struct {
+	Mode string
+	// MinInterval is the minimum time between block-driven refreshes.
+	MinInterval time.Duration
+	// MaxInterval refreshes even when no new block was seen.
+	MaxInterval time.Duration
+	// Jitter adds a random delay of up to Jitter to block-driven refreshes so
+	// several instances do not hit the RPC at once.
+	Jitter time.Duration
+	// PollInterval is how often eth_blockNumber is polled.
+	PollInterval time.Duration
+	// WSURL is the WebSocket RPC endpoint used by RefreshSubscribe.
+	WSURL string
+	// CacheMaxAge is how long cached state and price are served before
+	// services fall through to a live read; 0 disables expiry.
+	CacheMaxAge time.Duration
+}
+
+// RPCCacheConfig configures the read-through cache of per-user and per-loan
+// RPC reads.
+type RPCCacheConfig struct {
+	// TTL is how long a read is served; 0 disables the cache.
+	TTL time.Duration
+	// Size bounds the number of cached entries.
+	Size int
+}
+
+// HealthConfig configures the readiness checks.
+type HealthConfig struct {
+	// MaxHeadLag is how far the RPC's head block may trail wall-clock time
+	// before the node is considered stuck or syncing.
+	MaxHeadLag time.Duration
+	// CheckTimeout bounds each RPC made by a readiness check.
+	CheckTimeout time.Duration
+}
+
+// StreamConfig configures the /stream SSE and WebSocket endpoints.
+type StreamConfig struct {
+	// AllowedOrigins are the browser origins allowed to open a WebSocket;
+	// "*" allows any and empty allows only the API's own origin.
+	AllowedOrigins []string
+	// MaxWatchedLoans caps the distinct loans watched across all clients,
+	// each costing a getLoanHealth call per refresh.
+	MaxWatchedLoans int
+}
+
+// ShutdownConfig configures graceful shutdown.
+type ShutdownConfig struct {
+	// DrainDelay is how long readiness reports not ready before the server
+	// stops accepting connections, so load balancers can deregister it.
+	DrainDelay time.Duration
+	// Timeout bounds draining in-flight requests and stopping workers.
+	Timeout time.Duration
+}
+
+// Tracing exporters.
+const (
+	// TracingNone disables tracing.
+	TracingNone = "none"
+	// TracingStdout writes spans to stdout, for local testing.
+	TracingStdout = "stdout"
+	// TracingOTLP sends spans to an OTLP/HTTP collector configured by the
+	// standard OTEL_EXPORTER_OTLP_* variables.
+	TracingOTLP = "otlp"
+)
+
+// TracingConfig configures OpenTelemetry tracing.
+type TracingConfig struct {
+	Exporter    string
+	ServiceName string
+	// SampleRatio is the fraction of new traces sampled; traces started by a
+	// caller follow the caller's sampling decision.
+	SampleRatio float64
+}
+
+// Log formats.
+const (
+	LogJSON = "json"
+	LogText = "text"
+)
+
+// LogConfig configures structured logging.
+type LogConfig struct {
+	Format string
+	Level  slog.Level
+}
+
 // Config is the top-level application configuration.
 type Config struct {
 	Env         string
@@ -31,6 +229,38 @@ type Config struct {
 	ChainEnv    string
 	RPCURL      string
 	ChainConfig ChainConfig
+	// InterestRateBps overrides the fixed annual borrow rate mirrored from the
+	// LendingPool contract, in basis points. 0 means use the built-in default.
+	InterestRateBps int64
+	// SafeMultiSend is the Safe MultiSendCallOnly contract used to wrap batch
+	// transactions for Safe wallets.
+	SafeMultiSend string
+	// DataDir holds files persisted by the backend (faucet grants, history, ...).
+	// An empty DataDir keeps everything in memory only.
+	DataDir string
+	Faucet  FaucetConfig
+	Price   PriceConfig
+	// PoolSnapshotInterval is how often pool state snapshots are taken for charts.
+	PoolSnapshotInterval time.Duration
+	// PoolHistoryRetention is how long pool snapshots are kept.
+	PoolHistoryRetention time.Duration
+	Events               EventsConfig
+	// AdminToken guards /admin endpoints (bearer token); empty disables them.
+	AdminToken string
+	// MetricsToken guards /metrics (bearer token); empty leaves it open.
+	MetricsToken string
+	// TrustedProxies are the IPs / CIDRs whose X-Forwarded-For is believed
+	// when resolving client IPs for rate limits; empty uses the peer address.
+	TrustedProxies []string
+	Solvency       SolvencyConfig
+	Webhooks       WebhooksConfig
+	Refresh        StateRefreshConfig
+	RPCCache       RPCCacheConfig
+	Tracing        TracingConfig
+	Health         HealthConfig
+	Stream         StreamConfig
+	Shutdown       ShutdownConfig
+	Log            LogConfig
 }
 
 // Load loads configuration from environment variables and addresses.json.
@@ -59,15 +289,377 @@ func Load() (*Config, error) {
 		return nil, fmt.Errorf("missing RPC url env %s", chainCfg.RPCUrlEnv)
 	}
 
+	interestRateBps, err := getEnvInt64("INTEREST_RATE_BPS", 0)
+	if err != nil {
+		return nil, err
+	}
+	if interestRateBps < 0 {
+		return nil, fmt.Errorf("INTEREST_RATE_BPS must not be negative")
+	}
+
+	// Canonical MultiSendCallOnly v1.3.0 deployment, same address on BSC mainnet and testnet.
+	safeMultiSend := getEnv("SAFE_MULTISEND_ADDRESS", "0x40A2aCCbd92BCA938b02010E17A5b8929b49130D")
+
+	dataDir := os.Getenv("DATA_DIR")
+	if _, set := os.LookupEnv("DATA_DIR"); !set {
+		dataDir = "data"
+	}
+
+	faucet, err := loadFaucetConfig(dataDir)
+	if err != nil {
+		return nil, err
+	}
+
+	var price PriceConfig
+	if price.FeedHeartbeat, err = getEnvDuration("PRICE_FEED_HEARTBEAT", time.Hour); err != nil {
+		return nil, err
+	}
+	if price.MaxDeviationBps, err = getEnvInt64("PRICE_MAX_DEVIATION_BPS", 200); err != nil {
+		return nil, err
+	}
+	minSources, err := getEnvInt64("PRICE_MIN_SOURCES", 1)
+	if err != nil {
+		return nil, err
+	}
+	price.MinSources = int(minSources)
+	price.DexPool = os.Getenv("PRICE_DEX_POOL")
+	if price.DexTWAPWindow, err = getEnvDuration("PRICE_DEX_TWAP_WINDOW", 30*time.Minute); err != nil {
+		return nil, err
+	}
+	if price.DexBaseIsToken0, err = getEnvBool("PRICE_DEX_BASE_IS_TOKEN0", true); err != nil {
+		return nil, err
+	}
+	// WBNB and BSC USDT both use 18 decimals.
+	if price.DexBaseDecimals, err = getEnvInt64("PRICE_DEX_BASE_DECIMALS", 18); err != nil {
+		return nil, err
+	}
+	if price.DexQuoteDecimals, err = getEnvInt64("PRICE_DEX_QUOTE_DECIMALS", 18); err != nil {
+		return nil, err
+	}
+	if price.HistoryRetention, err = getEnvDuration("PRICE_HISTORY_RETENTION", 90*24*time.Hour); err != nil {
+		return nil, err
+	}
+
+	poolSnapshotInterval, err := getEnvDuration("POOL_SNAPSHOT_INTERVAL", 10*time.Minute)
+	if err != nil {
+		return nil, err
+	}
+	poolHistoryRetention, err := getEnvDuration("POOL_HISTORY_RETENTION", 90*24*time.Hour)
+	if err != nil {
+		return nil, err
+	}
+
+	events, err := loadEventsConfig(chainCfg)
+	if err != nil {
+		return nil, err
+	}
+
+	solvency := SolvencyConfig{AlertWebhookURL: os.Getenv("SOLVENCY_ALERT_WEBHOOK_URL")}
+	if solvency.Interval, err = getEnvDuration("SOLVENCY_INTERVAL", time.Minute); err != nil {
+		return nil, err
+	}
+	if solvency.CriticalShortfallBps, err = getEnvInt64("SOLVENCY_CRITICAL_SHORTFALL_BPS", 100); err != nil {
+		return nil, err
+	}
+	if solvency.CriticalShortfallBps < 0 {
+		return nil, fmt.Errorf("SOLVENCY_CRITICAL_SHORTFALL_BPS must not be negative")
+	}
+	if solvency.AlertRepeat, err = getEnvDuration("SOLVENCY_ALERT_REPEAT", time.Hour); err != nil {
+		return nil, err
+	}
+
+	webhooks, err := loadWebhooksConfig()
+	if err != nil {
+		return nil, err
+	}
+
+	refresh, err := loadStateRefreshConfig()
+	if err != nil {
+		return nil, err
+	}
+
+	var rpcCache RPCCacheConfig
+	if rpcCache.TTL, err = getEnvDuration("RPC_CACHE_TTL", 5*time.Second); err != nil {
+		return nil, err
+	}
+	cacheSize, err := getEnvInt64("RPC_CACHE_SIZE", 10000)
+	if err != nil {
+		return nil, err
+	}
+	if rpcCache.TTL < 0 || cacheSize <= 0 {
+		return nil, fmt.Errorf("RPC_CACHE_TTL must not be negative and RPC_CACHE_SIZE must be positive")
+	}
+	rpcCache.Size = int(cacheSize)
+
+	tracing, err := loadTracingConfig()
+	if err != nil {
+		return nil, err
+	}
+
+	var healthCfg HealthConfig
+	if healthCfg.MaxHeadLag, err = getEnvDuration("HEALTH_MAX_HEAD_LAG", time.Minute); err != nil {
+		return nil, err
+	}
+	if healthCfg.CheckTimeout, err = getEnvDuration("HEALTH_CHECK_TIMEOUT", 3*time.Second); err != nil {
+		return nil, err
+	}
+	if healthCfg.MaxHeadLag <= 0 || healthCfg.CheckTimeout <= 0 {
+		return nil, fmt.Errorf("HEALTH_MAX_HEAD_LAG and HEALTH_CHECK_TIMEOUT must be positive")
+	}
+
+	trustedProxies := getEnvList("TRUSTED_PROXIES")
+	for _, p := range trustedProxies {
+		if _, _, err := net.ParseCIDR(p); err != nil && net.ParseIP(p) == nil {
+			return nil, fmt.Errorf("invalid TRUSTED_PROXIES entry: %s", p)
+		}
+	}
+
+	stream := StreamConfig{AllowedOrigins: getEnvList("STREAM_ALLOWED_ORIGINS")}
+	maxWatched, err := getEnvInt64("STREAM_MAX_WATCHED_LOANS", 1000)
+	if err != nil {
+		return nil, err
+	}
+	if maxWatched <= 0 {
+		return nil, fmt.Errorf("STREAM_MAX_WATCHED_LOANS must be positive")
+	}
+	stream.MaxWatchedLoans = int(maxWatched)
+
+	logCfg, err := loadLogConfig(env)
+	if err != nil {
+		return nil, err
+	}
+
+	var shutdown ShutdownConfig
+	if shutdown.DrainDelay, err = getEnvDuration("SHUTDOWN_DRAIN_DELAY", 5*time.Second); err != nil {
+		return nil, err
+	}
+	if shutdown.Timeout, err = getEnvDuration("SHUTDOWN_TIMEOUT", 20*time.Second); err != nil {
+		return nil, err
+	}
+	if shutdown.DrainDelay < 0 || shutdown.Timeout <= 0 {
+		return nil, fmt.Errorf("SHUTDOWN_DRAIN_DELAY must not be negative and SHUTDOWN_TIMEOUT must be positive")
+	}
+
 	return &Config{
-		Env:         env,
-		HTTPPort:    httpPort,
-		ChainEnv:    chainEnv,
-		RPCURL:      rpcURL,
-		ChainConfig: chainCfg,
+		Env:             env,
+		HTTPPort:        httpPort,
+		ChainEnv:        chainEnv,
+		RPCURL:          rpcURL,
+		ChainConfig:     chainCfg,
+		InterestRateBps: interestRateBps,
+		SafeMultiSend:   safeMultiSend,
+		DataDir:         dataDir,
+		Faucet:          faucet,
+		Price:           price,
+
+		PoolSnapshotInterval: poolSnapshotInterval,
+		PoolHistoryRetention: poolHistoryRetention,
+		Events:               events,
+		AdminToken:           os.Getenv("ADMIN_TOKEN"),
+		MetricsToken:         os.Getenv("METRICS_TOKEN"),
+		TrustedProxies:       trustedProxies,
+		Solvency:             solvency,
+		Webhooks:             webhooks,
+		Refresh:              refresh,
+		RPCCache:             rpcCache,
+		Tracing:              tracing,
+		Health:               healthCfg,
+		Stream:               stream,
+		Shutdown:             shutdown,
+		Log:                  logCfg,
 	}, nil
 }
 
+func loadStateRefreshConfig() (StateRefreshConfig, error) {
+	cfg := StateRefreshConfig{
+		Mode:  getEnv("STATE_REFRESH_MODE", RefreshInterval),
+		WSURL: os.Getenv("RPC_WS_URL"),
+	}
+
+	var err error
+	if cfg.MinInterval, err = getEnvDuration("STATE_REFRESH_MIN_INTERVAL", 3*time.Second); err != nil {
+		return cfg, err
+	}
+	if cfg.MaxInterval, err = getEnvDuration("STATE_REFRESH_MAX_INTERVAL", 3*time.Minute); err != nil {
+		return cfg, err
+	}
+	if cfg.Jitter, err = getEnvDuration("STATE_REFRESH_JITTER", 500*time.Millisecond); err != nil {
+		return cfg, err
+	}
+	if cfg.PollInterval, err = getEnvDuration("STATE_REFRESH_POLL_INTERVAL", 3*time.Second); err != nil {
+		return cfg, err
+	}
+	// by default tolerate two missed refreshes.
+	if cfg.CacheMaxAge, err = getEnvDuration("STATE_CACHE_MAX_AGE", 3*cfg.MaxInterval); err != nil {
+		return cfg, err
+	}
+
+	switch cfg.Mode {
+	case RefreshInterval, RefreshPoll:
+	case RefreshSubscribe:
+		if cfg.WSURL == "" {
+			return cfg, fmt.Errorf("STATE_REFRESH_MODE=subscribe requires RPC_WS_URL")
+		}
+	default:
+		return cfg, fmt.Errorf("unsupported STATE_REFRESH_MODE: %s", cfg.Mode)
+	}
+	if cfg.MinInterval < 0 || cfg.Jitter < 0 || cfg.MaxInterval <= 0 || cfg.PollInterval <= 0 || cfg.CacheMaxAge < 0 {
+		return cfg, fmt.Errorf("STATE_REFRESH_* intervals out of range")
+	}
+	return cfg, nil
+}
+
+func loadTracingConfig() (TracingConfig, error) {
+	cfg := TracingConfig{
+		Exporter:    getEnv("TRACING_EXPORTER", TracingNone),
+		ServiceName: getEnv("OTEL_SERVICE_NAME", "cina-dex-backend"),
+	}
+	switch cfg.Exporter {
+	case TracingNone, TracingStdout, TracingOTLP:
+	default:
+		return cfg, fmt.Errorf("unsupported TRACING_EXPORTER: %s", cfg.Exporter)
+	}
+
+	var err error
+	if cfg.SampleRatio, err = getEnvFloat("TRACING_SAMPLE_RATIO", 1); err != nil {
+		return cfg, err
+	}
+	if cfg.SampleRatio < 0 || cfg.SampleRatio > 1 {
+		return cfg, fmt.Errorf("TRACING_SAMPLE_RATIO must be between 0 and 1")
+	}
+	return cfg, nil
+}
+
+// loadLogConfig reads LOG_FORMAT (json in prod, text elsewhere by default)
+// and LOG_LEVEL (debug, info, warn or error; default info).
+func loadLogConfig(env string) (LogConfig, error) {
+	format := LogText
+	if env == "prod" {
+		format = LogJSON
+	}
+	cfg := LogConfig{Format: getEnv("LOG_FORMAT", format)}
+	if cfg.Format != LogJSON && cfg.Format != LogText {
+		return cfg, fmt.Errorf("unsupported LOG_FORMAT: %s", cfg.Format)
+	}
+	if err := cfg.Level.UnmarshalText([]byte(getEnv("LOG_LEVEL", "info"))); err != nil {
+		return cfg, fmt.Errorf("invalid LOG_LEVEL: %w", err)
+	}
+	return cfg, nil
+}
+
+func loadWebhooksConfig() (WebhooksConfig, error) {
+	var cfg WebhooksConfig
+
+	maxSubs, err := getEnvInt64("WEBHOOKS_MAX_SUBSCRIPTIONS", 1000)
+	if err != nil {
+		return cfg, err
+	}
+	maxAttempts, err := getEnvInt64("WEBHOOKS_MAX_ATTEMPTS", 5)
+	if err != nil {
+		return cfg, err
+	}
+	perClient, err := getEnvInt64("WEBHOOKS_MAX_PER_CLIENT", 10)
+	if err != nil {
+		return cfg, err
+	}
+	perTarget, err := getEnvInt64("WEBHOOKS_MAX_PER_TARGET", 10)
+	if err != nil {
+		return cfg, err
+	}
+	if maxSubs <= 0 || maxAttempts <= 0 || perClient <= 0 || perTarget <= 0 {
+		return cfg, fmt.Errorf("WEBHOOKS_MAX_SUBSCRIPTIONS / WEBHOOKS_MAX_ATTEMPTS / WEBHOOKS_MAX_PER_CLIENT / WEBHOOKS_MAX_PER_TARGET must be positive")
+	}
+	cfg.MaxSubscriptions = int(maxSubs)
+	cfg.MaxAttempts = int(maxAttempts)
+	cfg.MaxPerClient = int(perClient)
+	cfg.MaxPerTarget = int(perTarget)
+
+	if cfg.RetryBackoff, err = getEnvDuration("WEBHOOKS_RETRY_BACKOFF", 5*time.Second); err != nil {
+		return cfg, err
+	}
+	if cfg.Timeout, err = getEnvDuration("WEBHOOKS_TIMEOUT", 10*time.Second); err != nil {
+		return cfg, err
+	}
+	// enable explicitly for a local receiver such as cmd/webhook-receiver.
+	if cfg.AllowPrivate, err = getEnvBool("WEBHOOKS_ALLOW_PRIVATE", false); err != nil {
+		return cfg, err
+	}
+	return cfg, nil
+}
+
+func loadEventsConfig(chainCfg ChainConfig) (EventsConfig, error) {
+	var cfg EventsConfig
+
+	start, err := getEnvInt64("EVENTS_START_BLOCK", int64(chainCfg.DeployBlock))
+	if err != nil {
+		return cfg, err
+	}
+	// BSC public RPCs commonly cap eth_getLogs at 5000 blocks.
+	chunk, err := getEnvInt64("EVENTS_CHUNK_SIZE", 5000)
+	if err != nil {
+		return cfg, err
+	}
+	confirmations, err := getEnvInt64("EVENTS_CONFIRMATIONS", 15)
+	if err != nil {
+		return cfg, err
+	}
+	if start < 0 || chunk <= 0 || confirmations < 0 {
+		return cfg, fmt.Errorf("EVENTS_START_BLOCK / EVENTS_CHUNK_SIZE / EVENTS_CONFIRMATIONS out of range")
+	}
+	cfg.StartBlock = uint64(start)
+	cfg.ChunkSize = uint64(chunk)
+	cfg.Confirmations = uint64(confirmations)
+
+	cfg.ABIDir = getEnv("EVENTS_ABI_DIR", "go_back/abi")
+	if cfg.PollInterval, err = getEnvDuration("EVENTS_POLL_INTERVAL", 30*time.Second); err != nil {
+		return cfg, err
+	}
+	return cfg, nil
+}
+
+func loadFaucetConfig(dataDir string) (FaucetConfig, error) {
+	cfg := FaucetConfig{
+		KeystorePath:     os.Getenv("FAUCET_KEYSTORE_PATH"),
+		KeystorePassword: os.Getenv("FAUCET_KEYSTORE_PASSWORD"),
+		USDTAmount:       getEnv("FAUCET_USDT_AMOUNT", "1000000000"), // 1000 USDT
+		BNBAmount:        getEnv("FAUCET_BNB_AMOUNT", "0"),
+		GrantsPath:       dataPath(dataDir, "faucet_grants.jsonl"),
+	}
+
+	var err error
+	if cfg.Cooldown, err = getEnvDuration("FAUCET_COOLDOWN", 24*time.Hour); err != nil {
+		return cfg, err
+	}
+	if cfg.Window, err = getEnvDuration("FAUCET_WINDOW", 24*time.Hour); err != nil {
+		return cfg, err
+	}
+	addrLimit, err := getEnvInt64("FAUCET_ADDRESS_LIMIT", 1)
+	if err != nil {
+		return cfg, err
+	}
+	ipLimit, err := getEnvInt64("FAUCET_IP_LIMIT", 3)
+	if err != nil {
+		return cfg, err
+	}
+	cfg.AddressLimit = int(addrLimit)
+	cfg.IPLimit = int(ipLimit)
+
+	return cfg, nil
+}
+
+// DataPath returns the path of a persisted file under DataDir, or "" when
+// persistence is disabled.
+func (c *Config) DataPath(name string) string {
+	return dataPath(c.DataDir, name)
+}
+
+func dataPath(dataDir, name string) string {
+	if dataDir == "" {
+		return ""
+	}
+	return filepath.Join(dataDir, name)
+}
+
 func getEnv(key, def string) string {
 	if v := os.Getenv(key); v != "" {
 		return v
@@ -75,6 +667,65 @@ func getEnv(key, def string) string {
 	return def
 }
 
+// getEnvList splits a comma-separated variable, dropping empty entries.
+func getEnvList(key string) []string {
+	var out []string
+	for _, v := range strings.Split(os.Getenv(key), ",") {
+		if v = strings.TrimSpace(v); v != "" {
+			out = append(out, v)
+		}
+	}
+	return out
+}
+
+func getEnvInt64(key string, def int64) (int64, error) {
+	v := os.Getenv(key)
+	if v == "" {
+		return def, nil
+	}
+	n, err := strconv.ParseInt(v, 10, 64)
+	if err != nil {
+		return 0, fmt.Errorf("invalid %s: %w", key, err)
+	}
+	return n, nil
+}
+
+func getEnvBool(key string, def bool) (bool, error) {
+	v := os.Getenv(key)
+	if v == "" {
+		return def, nil
+	}
+	b, err := strconv.ParseBool(v)
+	if err != nil {
+		return false, fmt.Errorf("invalid %s: %w", key, err)
+	}
+	return b, nil
+}
+
+func getEnvFloat(key string, def float64) (float64, error) {
+	v := os.Getenv(key)
+	if v == "" {
+		return def, nil
+	}
+	f, err := strconv.ParseFloat(v, 64)
+	if err != nil {
+		return 0, fmt.Errorf("invalid %s: %w", key, err)
+	}
+	return f, nil
+}
+
+func getEnvDuration(key string, def time.Duration) (time.Duration, error) {
+	v := os.Getenv(key)
+	if v == "" {
+		return def, nil
+	}
+	d, err := time.ParseDuration(v)
+	if err != nil {
+		return 0, fmt.Errorf("invalid %s: %w", key, err)
+	}
+	return d, nil
+}
+
 func loadAddresses(path string) (*Addresses, error) {
 	bz, err := os.ReadFile(path)
 	if err != nil {
@@ -0,0 +1,118 @@
+// Package health tracks background workers so readiness checks can report
+// workers that stopped succeeding.
+package health
+
+import (
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/cina_dex_backend/internal/model"
+)
+
+type worker struct {
+	maxSilence time.Duration
+	critical   bool
+
+	registered  time.Time
+	lastRun     time.Time
+	lastSuccess time.Time
+	lastErr     error
+}
+
+var (
+	mu           sync.Mutex
+	workers      = make(map[string]*worker)
+	shuttingDown bool
+)
+
+// SetShuttingDown makes readiness fail so load balancers stop routing new
+// requests before the server drains.
+func SetShuttingDown() {
+	mu.Lock()
+	defer mu.Unlock()
+	shuttingDown = true
+}
+
+// Shutdown reports the shutdown component: down once SetShuttingDown was
+// called, nil before.
+func Shutdown() *model.ComponentStatus {
+	mu.Lock()
+	defer mu.Unlock()
+	if !shuttingDown {
+		return nil
+	}
+	return &model.ComponentStatus{Name: "shutdown", Status: model.HealthDown, Message: "server is shutting down"}
+}
+
+// Register declares a background worker. A worker whose last run failed is
+// degraded. One that has not succeeded for maxSilence (counted from
+// registration; 0 disables the check) is degraded too, or down when critical,
+// making the service not ready.
+func Register(name string, maxSilence time.Duration, critical bool) {
+	mu.Lock()
+	defer mu.Unlock()
+	workers[name] = &worker{
+		maxSilence: maxSilence,
+		critical:   critical,
+		registered: time.Now(),
+	}
+}
+
+// Report records a run of a registered worker; runs of unregistered workers
+// are ignored.
+func Report(name string, err error) {
+	mu.Lock()
+	defer mu.Unlock()
+	w, ok := workers[name]
+	if !ok {
+		return
+	}
+	w.lastRun = time.Now()
+	w.lastErr = err
+	if err == nil {
+		w.lastSuccess = w.lastRun
+	}
+}
+
+// Workers returns the status of every registered worker, sorted by name.
+func Workers() []*model.ComponentStatus {
+	mu.Lock()
+	defer mu.Unlock()
+
+	now := time.Now()
+	out := make([]*model.ComponentStatus, 0, len(workers))
+	for name, w := range workers {
+		st := &model.ComponentStatus{
+			Name:    "worker:" + name,
+			Status:  model.HealthOK,
+			Details: map[string]interface{}{"critical": w.critical},
+		}
+		if !w.lastRun.IsZero() {
+			st.Details["lastRun"] = w.lastRun.Unix()
+		}
+		if !w.lastSuccess.IsZero() {
+			st.Details["lastSuccess"] = w.lastSuccess.Unix()
+		}
+
+		since := w.lastSuccess
+		if since.IsZero() {
+			since = w.registered
+		}
+		switch {
+		case w.maxSilence > 0 && now.Sub(since) > w.maxSilence:
+			st.Status = model.HealthDegraded
+			if w.critical {
+				st.Status = model.HealthDown
+			}
+			st.Message = fmt.Sprintf("no successful run for %s", now.Sub(since).Round(time.Second))
+		case w.lastErr != nil:
+			st.Status = model.HealthDegraded
+			st.Message = w.lastErr.Error()
+		}
+		out = append(out, st)
+	}
+	sort.Slice(out, func(i, j int) bool { return out[i].Name < out[j].Name })
+	return out
+}
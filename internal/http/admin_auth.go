@@ -0,0 +1,22 @@
+package http
+
+import (
+	"crypto/subtle"
+	"net/http"
+	"strings"
+
+	"github.com/cina_dex_backend/pkg/response"
+	"github.com/gin-gonic/gin"
+)
+
+// adminAuth requires "Authorization: Bearer <token>" on admin routes.
+func adminAuth(token string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		got, ok := strings.CutPrefix(c.GetHeader("Authorization"), "Bearer ")
+		if !ok || subtle.ConstantTimeCompare([]byte(got), []byte(token)) != 1 {
+			c.AbortWithStatusJSON(http.StatusUnauthorized, response.Error(c, 4010, "unauthorized"))
+			return
+		}
+		c.Next()
+	}
+}
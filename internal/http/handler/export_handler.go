@@ -0,0 +1,144 @@
+package handler
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/cina_dex_backend/internal/service"
+	"github.com/cina_dex_backend/pkg/response"
+	"github.com/gin-gonic/gin"
+)
+
+// defaultExportRange is the admin export range when no "from" is given.
+const defaultExportRange = 30 * 24 * time.Hour
+
+// ExportHandler streams CSV / JSON exports for accounting.
+type ExportHandler struct {
+	exportSvc service.ExportService
+}
+
+func NewExportHandler(exportSvc service.ExportService) *ExportHandler {
+	return &ExportHandler{exportSvc: exportSvc}
+}
+
+// ExportUser streams a user's activity history.
+// Query: format (csv | json, default csv), from / to (unix seconds; default all history).
+func (h *ExportHandler) ExportUser(c *gin.Context) {
+	address := c.Param("address")
+	if address == "" {
+		c.JSON(http.StatusBadRequest, response.Error(c, 4001, "address is required"))
+		return
+	}
+	from, to, ok := exportRange(c, time.Unix(0, 0))
+	if !ok {
+		return
+	}
+	format := c.DefaultQuery("format", service.ExportFormatCSV)
+
+	h.stream(c, "activity_"+address, format, func(ctx context.Context, w io.Writer) error {
+		return h.exportSvc.ExportUser(ctx, w, address, format, from, to)
+	})
+}
+
+// ExportEvents streams all indexed pool events (admin only).
+// Query: format (csv | json, default csv), from / to (unix seconds; default last 30 days).
+func (h *ExportHandler) ExportEvents(c *gin.Context) {
+	from, to, ok := exportRange(c, time.Now().Add(-defaultExportRange))
+	if !ok {
+		return
+	}
+	format := c.DefaultQuery("format", service.ExportFormatCSV)
+
+	h.stream(c, "pool_events", format, func(ctx context.Context, w io.Writer) error {
+		return h.exportSvc.ExportEvents(ctx, w, format, from, to)
+	})
+}
+
+// ExportLoans streams all loans started in the range (admin only).
+// Query: format (csv | json, default csv), from / to (unix seconds; default last 30 days).
+func (h *ExportHandler) ExportLoans(c *gin.Context) {
+	from, to, ok := exportRange(c, time.Now().Add(-defaultExportRange))
+	if !ok {
+		return
+	}
+	format := c.DefaultQuery("format", service.ExportFormatCSV)
+
+	h.stream(c, "loans", format, func(ctx context.Context, w io.Writer) error {
+		return h.exportSvc.ExportLoans(ctx, w, format, from, to)
+	})
+}
+
+// stream sets download headers and runs export against the response body.
+// Errors before the first byte are reported as JSON; later errors can only
+// abort the download.
+func (h *ExportHandler) stream(c *gin.Context, name, format string, export func(ctx context.Context, w io.Writer) error) {
+	if !service.ValidExportFormat(format) {
+		c.JSON(http.StatusBadRequest, response.Error(c, 4001, "format must be csv or json"))
+		return
+	}
+	contentType := "text/csv; charset=utf-8"
+	if format == service.ExportFormatJSON {
+		contentType = "application/json; charset=utf-8"
+	}
+	c.Header("Content-Type", contentType)
+	c.Header("Content-Disposition", fmt.Sprintf(`attachment; filename="%s.%s"`, name, format))
+
+	err := export(c.Request.Context(), &flushWriter{w: c.Writer})
+	if err == nil {
+		return
+	}
+	if !c.Writer.Written() {
+		c.Writer.Header().Del("Content-Disposition")
+		c.Writer.Header().Del("Content-Type")
+		if errors.Is(err, service.ErrInvalidExport) {
+			c.JSON(http.StatusBadRequest, response.Error(c, 4001, err.Error()))
+			return
+		}
+		c.JSON(http.StatusInternalServerError, response.Error(c, 1001, err.Error()))
+		return
+	}
+	slog.ErrorContext(c.Request.Context(), "export failed", "export", name, "err", err)
+	c.Abort()
+}
+
+// exportRange parses from / to query parameters (unix seconds).
+func exportRange(c *gin.Context, defaultFrom time.Time) (time.Time, time.Time, bool) {
+	from, to := defaultFrom, time.Now()
+	for _, p := range []struct {
+		key string
+		dst *time.Time
+	}{{"from", &from}, {"to", &to}} {
+		raw := c.Query(p.key)
+		if raw == "" {
+			continue
+		}
+		ts, err := strconv.ParseInt(raw, 10, 64)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, response.Error(c, 4001, p.key+" must be a unix timestamp"))
+			return from, to, false
+		}
+		*p.dst = time.Unix(ts, 0)
+	}
+	if !from.Before(to) {
+		c.JSON(http.StatusBadRequest, response.Error(c, 4001, "from must be before to"))
+		return from, to, false
+	}
+	return from, to, true
+}
+
+// flushWriter pushes every chunk to the client so large exports stream.
+type flushWriter struct {
+	w gin.ResponseWriter
+}
+
+func (f *flushWriter) Write(p []byte) (int, error) {
+	n, err := f.w.Write(p)
+	f.w.Flush()
+	return n, err
+}
@@ -0,0 +1,62 @@
+package handler
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/cina_dex_backend/internal/service"
+	"github.com/cina_dex_backend/pkg/response"
+	"github.com/gin-gonic/gin"
+)
+
+// FaucetHandler exposes the server-side testnet faucet.
+type FaucetHandler struct {
+	faucetSvc service.FaucetService
+}
+
+func NewFaucetHandler(faucetSvc service.FaucetService) *FaucetHandler {
+	return &FaucetHandler{faucetSvc: faucetSvc}
+}
+
+type faucetClaimRequest struct {
+	// Address receives the minted MockUSDT (and BNB drip, if enabled).
+	Address string `json:"address" binding:"required"`
+}
+
+// Claim mints MockUSDT to the given address, signed by the backend faucet wallet.
+func (h *FaucetHandler) Claim(c *gin.Context) {
+	var req faucetClaimRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, response.Error(c, 4001, err.Error()))
+		return
+	}
+
+	grant, err := h.faucetSvc.Claim(c.Request.Context(), req.Address, c.ClientIP())
+	if err != nil {
+		if errors.Is(err, service.ErrFaucetRateLimited) {
+			c.JSON(http.StatusTooManyRequests, response.Error(c, 4290, err.Error()))
+			return
+		}
+		c.JSON(http.StatusInternalServerError, response.Error(c, 1001, err.Error()))
+		return
+	}
+
+	c.JSON(http.StatusOK, response.Success(grant))
+}
+
+// Status returns whether an address can claim now and its recent grants.
+func (h *FaucetHandler) Status(c *gin.Context) {
+	address := c.Param("address")
+	if address == "" {
+		c.JSON(http.StatusBadRequest, response.Error(c, 4001, "address is required"))
+		return
+	}
+
+	status, err := h.faucetSvc.Status(c.Request.Context(), address)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, response.Error(c, 1001, err.Error()))
+		return
+	}
+
+	c.JSON(http.StatusOK, response.Success(status))
+}
@@ -2,14 +2,89 @@ package handler
 
 import (
 	"net/http"
+	"time"
 
+	"github.com/cina_dex_backend/internal/model"
+	"github.com/cina_dex_backend/internal/onchain"
+	"github.com/cina_dex_backend/internal/service"
 	"github.com/cina_dex_backend/pkg/response"
 	"github.com/gin-gonic/gin"
 )
 
-// Health is a simple liveness endpoint.
-func Health(c *gin.Context) {
-	c.JSON(http.StatusOK, response.Success(map[string]interface{}{
-		"status": "ok",
-	}))
+// HealthHandler exposes service health, including the cached price checks.
+type HealthHandler struct {
+	cache     *service.StateCache
+	rpcCache  *onchain.CachedClient
+	readiness service.ReadinessService
+}
+
+// NewHealthHandler constructs a HealthHandler; rpcCache and readiness may be nil.
+func NewHealthHandler(cache *service.StateCache, rpcCache *onchain.CachedClient, readiness service.ReadinessService) *HealthHandler {
+	return &HealthHandler{cache: cache, rpcCache: rpcCache, readiness: readiness}
+}
+
+// Live is the liveness probe: it answers as long as the process serves HTTP
+// and checks no dependencies, so a dead RPC does not get the pod restarted.
+func (h *HealthHandler) Live(c *gin.Context) {
+	c.JSON(http.StatusOK, response.Success(gin.H{"status": "ok"}))
+}
+
+// Ready is the readiness probe. It returns 503 with the per-component status
+// when any component is down.
+func (h *HealthHandler) Ready(c *gin.Context) {
+	if h.readiness == nil {
+		c.JSON(http.StatusOK, response.Success(&model.Readiness{Status: model.HealthOK, Ready: true, CheckedAt: time.Now().Unix()}))
+		return
+	}
+	r := h.readiness.Ready(c.Request.Context())
+	if !r.Ready {
+		c.JSON(http.StatusServiceUnavailable, response.ErrorWithData(c, 5030, "not ready", r))
+		return
+	}
+	c.JSON(http.StatusOK, response.Success(r))
+}
+
+// Health is a simple liveness endpoint. It always returns 200; status is
+// "degraded" when the cached price is flagged unsafe or a cached value has
+// outlived the cache max age (refreshes keep failing).
+func (h *HealthHandler) Health(c *gin.Context) {
+	status := "ok"
+	data := map[string]interface{}{}
+
+	if h.cache != nil {
+		if info, ok := h.cache.GetPriceInfo(); ok {
+			data["price"] = map[string]interface{}{
+				"stale":     info.Stale,
+				"deviating": info.Deviating,
+				"unsafe":    info.Unsafe,
+				"updatedAt": info.UpdatedAt,
+				"checkedAt": info.CheckedAt,
+				"warnings":  info.Warnings,
+			}
+			if info.Unsafe {
+				status = "degraded"
+			}
+		}
+
+		cache := map[string]interface{}{}
+		if st, ok := h.cache.PoolStateStatus(); ok {
+			cache["poolState"] = st
+			if st.Expired {
+				status = "degraded"
+			}
+		}
+		if st, ok := h.cache.PriceStatus(); ok {
+			cache["price"] = st
+			if st.Expired {
+				status = "degraded"
+			}
+		}
+		data["cache"] = cache
+	}
+	if h.rpcCache != nil {
+		data["rpcCache"] = h.rpcCache.Stats()
+	}
+
+	data["status"] = status
+	c.JSON(http.StatusOK, response.Success(data))
 }
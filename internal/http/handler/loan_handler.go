@@ -27,7 +27,7 @@ func (h *LoanHandler) GetLoan(c *gin.Context) {
 
 	loan, err := h.loanSvc.GetLoan(c.Request.Context(), loanID)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, response.Error(1001, err.Error()))
+		c.JSON(http.StatusInternalServerError, response.Error(c, 1001, err.Error()))
 		return
 	}
 	c.JSON(http.StatusOK, response.Success(loan))
@@ -42,7 +42,7 @@ func (h *LoanHandler) GetLoanHealth(c *gin.Context) {
 
 	health, err := h.loanSvc.GetLoanHealth(c.Request.Context(), loanID)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, response.Error(1001, err.Error()))
+		c.JSON(http.StatusInternalServerError, response.Error(c, 1001, err.Error()))
 		return
 	}
 	c.JSON(http.StatusOK, response.Success(health))
@@ -51,12 +51,12 @@ func (h *LoanHandler) GetLoanHealth(c *gin.Context) {
 func parseLoanID(c *gin.Context) (uint64, bool) {
 	raw := c.Param("loanId")
 	if raw == "" {
-		c.JSON(http.StatusBadRequest, response.Error(4001, "loanId is required"))
+		c.JSON(http.StatusBadRequest, response.Error(c, 4001, "loanId is required"))
 		return 0, false
 	}
 	id, err := strconv.ParseUint(raw, 10, 64)
 	if err != nil {
-		c.JSON(http.StatusBadRequest, response.Error(4002, "loanId must be a uint"))
+		c.JSON(http.StatusBadRequest, response.Error(c, 4002, "loanId must be a uint"))
 		return 0, false
 	}
 	return id, true
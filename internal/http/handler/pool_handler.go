@@ -1,7 +1,10 @@
 package handler
 
 import (
+	"errors"
 	"net/http"
+	"strconv"
+	"time"
 
 	"github.com/cina_dex_backend/internal/service"
 	"github.com/cina_dex_backend/pkg/response"
@@ -10,18 +13,136 @@ import (
 
 // PoolHandler exposes pool-related read APIs.
 type PoolHandler struct {
-	poolSvc service.PoolService
+	poolSvc    service.PoolService
+	metricsSvc service.PoolMetricsService
+	revenueSvc service.RevenueService
+	solvency   service.SolvencyService
 }
 
-func NewPoolHandler(poolSvc service.PoolService) *PoolHandler {
-	return &PoolHandler{poolSvc: poolSvc}
+func NewPoolHandler(poolSvc service.PoolService, metricsSvc service.PoolMetricsService, revenueSvc service.RevenueService, solvency service.SolvencyService) *PoolHandler {
+	return &PoolHandler{
+		poolSvc:    poolSvc,
+		metricsSvc: metricsSvc,
+		revenueSvc: revenueSvc,
+		solvency:   solvency,
+	}
 }
+
 // GetPoolState returns aggregated pool state for the frontend dashboard.
 func (h *PoolHandler) GetPoolState(c *gin.Context) {
 	state, err := h.poolSvc.GetPoolState(c.Request.Context())
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, response.Error(1001, err.Error()))
+		c.JSON(http.StatusInternalServerError, response.Error(c, 1001, err.Error()))
 		return
 	}
 	c.JSON(http.StatusOK, response.Success(state))
 }
+
+// GetPoolAPY returns trailing realized and forward estimated supply yields.
+func (h *PoolHandler) GetPoolAPY(c *gin.Context) {
+	apy, err := h.poolSvc.GetPoolAPY(c.Request.Context())
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, response.Error(c, 1001, err.Error()))
+		return
+	}
+	c.JSON(http.StatusOK, response.Success(apy))
+}
+
+// GetPoolRevenue returns interest earned from repays and liquidations per day
+// and cumulatively, plus interest outstanding on active loans.
+// Query: from / to (unix seconds; default all indexed history).
+func (h *PoolHandler) GetPoolRevenue(c *gin.Context) {
+	from, to := time.Unix(0, 0), time.Now()
+	if raw := c.Query("from"); raw != "" {
+		ts, err := strconv.ParseInt(raw, 10, 64)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, response.Error(c, 4001, "from must be a unix timestamp"))
+			return
+		}
+		from = time.Unix(ts, 0)
+	}
+	if raw := c.Query("to"); raw != "" {
+		ts, err := strconv.ParseInt(raw, 10, 64)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, response.Error(c, 4001, "to must be a unix timestamp"))
+			return
+		}
+		to = time.Unix(ts, 0)
+	}
+
+	revenue, err := h.revenueSvc.Revenue(c.Request.Context(), from, to)
+	if err != nil {
+		if errors.Is(err, service.ErrInvalidRevenueRange) {
+			c.JSON(http.StatusBadRequest, response.Error(c, 4001, err.Error()))
+			return
+		}
+		c.JSON(http.StatusInternalServerError, response.Error(c, 1001, err.Error()))
+		return
+	}
+	c.JSON(http.StatusOK, response.Success(revenue))
+}
+
+// GetPoolSolvency returns the monitor's latest bad-debt / solvency check of
+// active loans.
+func (h *PoolHandler) GetPoolSolvency(c *gin.Context) {
+	report, err := h.solvency.Report(c.Request.Context())
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, response.Error(c, 1001, err.Error()))
+		return
+	}
+	c.JSON(http.StatusOK, response.Success(report))
+}
+
+// CheckPoolSolvency revalues all active loans now (admin only); each check
+// reads every active loan on-chain.
+func (h *PoolHandler) CheckPoolSolvency(c *gin.Context) {
+	report, err := h.solvency.Check(c.Request.Context())
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, response.Error(c, 1001, err.Error()))
+		return
+	}
+	c.JSON(http.StatusOK, response.Success(report))
+}
+
+// GetPoolMetric returns a time series of a pool metric for charts.
+// Path: metric (tvl, borrowed, available-liquidity, exchange-rate, utilization).
+// Query: range (e.g. 24h, 7d, 30d; default 7d), interval (default depends on range).
+func (h *PoolHandler) GetPoolMetric(c *gin.Context) {
+	span, err := parseInterval(c.DefaultQuery("range", "7d"))
+	if err != nil || span <= 0 {
+		c.JSON(http.StatusBadRequest, response.Error(c, 4001, "invalid range"))
+		return
+	}
+
+	interval := defaultMetricInterval(span)
+	if raw := c.Query("interval"); raw != "" {
+		if interval, err = parseInterval(raw); err != nil {
+			c.JSON(http.StatusBadRequest, response.Error(c, 4001, "invalid interval"))
+			return
+		}
+	}
+
+	to := time.Now()
+	series, err := h.metricsSvc.Series(c.Request.Context(), c.Param("metric"), to.Add(-span), to, interval)
+	if err != nil {
+		if errors.Is(err, service.ErrInvalidMetricQuery) {
+			c.JSON(http.StatusBadRequest, response.Error(c, 4001, err.Error()))
+			return
+		}
+		c.JSON(http.StatusInternalServerError, response.Error(c, 1001, err.Error()))
+		return
+	}
+	c.JSON(http.StatusOK, response.Success(series))
+}
+
+// defaultMetricInterval picks a chart resolution of roughly 100-200 points.
+func defaultMetricInterval(span time.Duration) time.Duration {
+	switch {
+	case span <= 24*time.Hour:
+		return 15 * time.Minute
+	case span <= 7*24*time.Hour:
+		return time.Hour
+	default:
+		return 4 * time.Hour
+	}
+}
@@ -0,0 +1,83 @@
+package handler
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/cina_dex_backend/internal/service"
+	"github.com/cina_dex_backend/pkg/response"
+	"github.com/gin-gonic/gin"
+)
+
+// defaultCandleCount is how many candles are returned when no "from" is given.
+const defaultCandleCount = 100
+
+// PriceHandler exposes historical BNB/USD prices.
+type PriceHandler struct {
+	historySvc service.PriceHistoryService
+}
+
+func NewPriceHandler(historySvc service.PriceHistoryService) *PriceHandler {
+	return &PriceHandler{historySvc: historySvc}
+}
+
+// GetBnbUsdOHLC returns OHLC candles of recorded BNB/USD prices.
+// Query: interval (1m,5m,15m,30m,1h,4h,1d; default 1h), from / to (unix
+// seconds; default the last 100 intervals), source (oracle, median,
+// chainlink; default oracle, the price the contract uses).
+func (h *PriceHandler) GetBnbUsdOHLC(c *gin.Context) {
+	interval := c.DefaultQuery("interval", "1h")
+	source := c.DefaultQuery("source", service.PriceSourceOracle)
+
+	to := time.Now()
+	if raw := c.Query("to"); raw != "" {
+		ts, err := strconv.ParseInt(raw, 10, 64)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, response.Error(c, 4001, "to must be a unix timestamp"))
+			return
+		}
+		to = time.Unix(ts, 0)
+	}
+
+	var from time.Time
+	if raw := c.Query("from"); raw != "" {
+		ts, err := strconv.ParseInt(raw, 10, 64)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, response.Error(c, 4001, "from must be a unix timestamp"))
+			return
+		}
+		from = time.Unix(ts, 0)
+	} else {
+		step, err := parseInterval(interval)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, response.Error(c, 4001, err.Error()))
+			return
+		}
+		from = to.Add(-defaultCandleCount * step)
+	}
+
+	candles, err := h.historySvc.OHLC(c.Request.Context(), source, interval, from, to)
+	if err != nil {
+		if errors.Is(err, service.ErrInvalidOHLC) {
+			c.JSON(http.StatusBadRequest, response.Error(c, 4001, err.Error()))
+			return
+		}
+		c.JSON(http.StatusInternalServerError, response.Error(c, 1001, err.Error()))
+		return
+	}
+	c.JSON(http.StatusOK, response.Success(candles))
+}
+
+// parseInterval accepts Go durations plus a "d" (day) suffix, e.g. "1h", "1d".
+func parseInterval(s string) (time.Duration, error) {
+	if n := len(s); n > 1 && s[n-1] == 'd' {
+		days, err := strconv.Atoi(s[:n-1])
+		if err != nil {
+			return 0, err
+		}
+		return time.Duration(days) * 24 * time.Hour, nil
+	}
+	return time.ParseDuration(s)
+}
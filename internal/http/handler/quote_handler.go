@@ -20,6 +20,17 @@ func NewQuoteHandler(quoteSvc service.QuoteService) *QuoteHandler {
 type borrowQuoteRequest struct {
 	// Amount is the desired USDT borrow amount in smallest units (6 decimals).
 	Amount string `json:"amount" binding:"required"`
+	// Duration is the intended loan duration in seconds; optional. When set,
+	// the quote includes repayment amount, interest, APR and maturity.
+	Duration uint64 `json:"duration"`
+}
+
+type repayQuoteRequest struct {
+	// UserAddress is the wallet that will send the repay tx; optional. When set,
+	// its USDT balance/allowance shortfall is included in the quote.
+	UserAddress string `json:"userAddress"`
+	// LoanIDs lists the loans to repay; several ids quote a "repay all" flow.
+	LoanIDs []uint64 `json:"loanIds" binding:"required,min=1"`
 }
 
 // QuoteBorrow computes the required BNB collateral (wei) for a given borrow amount.
@@ -28,13 +39,31 @@ type borrowQuoteRequest struct {
 func (h *QuoteHandler) QuoteBorrow(c *gin.Context) {
 	var req borrowQuoteRequest
 	if err := c.ShouldBindJSON(&req); err != nil {
-		c.JSON(http.StatusBadRequest, response.Error(4001, err.Error()))
+		c.JSON(http.StatusBadRequest, response.Error(c, 4001, err.Error()))
+		return
+	}
+
+	quote, err := h.quoteSvc.QuoteBorrowCollateral(c.Request.Context(), req.Amount, req.Duration)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, response.Error(c, 1001, err.Error()))
+		return
+	}
+
+	c.JSON(http.StatusOK, response.Success(quote))
+}
+
+// QuoteRepay returns principal/interest/APR breakdown for one or more loans,
+// plus the sender's USDT balance/allowance shortfall.
+func (h *QuoteHandler) QuoteRepay(c *gin.Context) {
+	var req repayQuoteRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, response.Error(c, 4001, err.Error()))
 		return
 	}
 
-	quote, err := h.quoteSvc.QuoteBorrowCollateral(c.Request.Context(), req.Amount)
+	quote, err := h.quoteSvc.QuoteRepay(c.Request.Context(), req.UserAddress, req.LoanIDs)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, response.Error(1001, err.Error()))
+		c.JSON(http.StatusInternalServerError, response.Error(c, 1001, err.Error()))
 		return
 	}
 
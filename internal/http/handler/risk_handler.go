@@ -0,0 +1,48 @@
+package handler
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/cina_dex_backend/internal/service"
+	"github.com/cina_dex_backend/pkg/response"
+	"github.com/gin-gonic/gin"
+)
+
+// RiskHandler exposes pool-wide risk analysis endpoints.
+type RiskHandler struct {
+	stressSvc service.StressService
+}
+
+func NewRiskHandler(stressSvc service.StressService) *RiskHandler {
+	return &RiskHandler{stressSvc: stressSvc}
+}
+
+type stressRequest struct {
+	// Prices are hypothetical BNB/USD prices, 18 decimals.
+	Prices []string `json:"prices"`
+	// Changes are percentage moves from the current price, e.g. -30.
+	Changes []float64 `json:"changes"`
+}
+
+// StressTest revalues all active loans at hypothetical BNB prices and reports
+// liquidations, liquidator payouts and projected bad debt per price.
+func (h *RiskHandler) StressTest(c *gin.Context) {
+	var req stressRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, response.Error(c, 4001, err.Error()))
+		return
+	}
+
+	result, err := h.stressSvc.Run(c.Request.Context(), req.Prices, req.Changes)
+	if err != nil {
+		if errors.Is(err, service.ErrInvalidStressScenario) {
+			c.JSON(http.StatusBadRequest, response.Error(c, 4001, err.Error()))
+			return
+		}
+		c.JSON(http.StatusInternalServerError, response.Error(c, 1001, err.Error()))
+		return
+	}
+
+	c.JSON(http.StatusOK, response.Success(result))
+}
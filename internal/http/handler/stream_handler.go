@@ -0,0 +1,260 @@
+package handler
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/cina_dex_backend/internal/model"
+	"github.com/cina_dex_backend/internal/service"
+	"github.com/cina_dex_backend/pkg/response"
+	"github.com/gin-gonic/gin"
+	"github.com/gorilla/websocket"
+)
+
+// streamHeartbeat keeps idle connections open through proxies.
+const streamHeartbeat = 15 * time.Second
+
+// StreamHandler pushes pool state, price and loan health changes over
+// Server-Sent Events or WebSocket.
+type StreamHandler struct {
+	hub      *service.StreamHub
+	loanSvc  service.LoanService
+	upgrader websocket.Upgrader
+}
+
+// NewStreamHandler constructs a StreamHandler. WebSocket connections are
+// accepted from allowedOrigins ("*" for any); with none, only from the
+// API's own origin.
+func NewStreamHandler(hub *service.StreamHub, loanSvc service.LoanService, allowedOrigins []string) *StreamHandler {
+	h := &StreamHandler{hub: hub, loanSvc: loanSvc}
+	if len(allowedOrigins) > 0 {
+		h.upgrader.CheckOrigin = checkOrigin(allowedOrigins)
+	}
+	return h
+}
+
+// checkOrigin allows requests without an Origin header (non-browser
+// clients) and those whose Origin is listed.
+func checkOrigin(allowed []string) func(r *http.Request) bool {
+	set := make(map[string]bool, len(allowed))
+	for _, o := range allowed {
+		set[strings.TrimSuffix(o, "/")] = true
+	}
+	return func(r *http.Request) bool {
+		origin := r.Header.Get("Origin")
+		return origin == "" || set["*"] || set[origin]
+	}
+}
+
+// subscribe parses the filter, checks that every watched loan is active and
+// subscribes to the hub. On failure it writes the error response and
+// returns false.
+func (h *StreamHandler) subscribe(c *gin.Context) (*service.StreamClient, []*model.StreamEvent, bool) {
+	filter, err := parseStreamFilter(c)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, response.Error(c, 4001, err.Error()))
+		return nil, nil, false
+	}
+	for id := range filter.Loans {
+		loan, err := h.loanSvc.GetLoan(c.Request.Context(), id)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, response.Error(c, 1001, err.Error()))
+			return nil, nil, false
+		}
+		if !loan.IsActive {
+			c.JSON(http.StatusBadRequest, response.Error(c, 4001, fmt.Sprintf("loan %d does not exist or is not active", id)))
+			return nil, nil, false
+		}
+	}
+	last, resume := lastEventID(c)
+	client, backlog, err := h.hub.Subscribe(filter, last, resume)
+	if err != nil {
+		if errors.Is(err, service.ErrStreamLimit) {
+			c.JSON(http.StatusTooManyRequests, response.Error(c, 4290, err.Error()))
+			return nil, nil, false
+		}
+		c.JSON(http.StatusInternalServerError, response.Error(c, 1001, err.Error()))
+		return nil, nil, false
+	}
+	return client, backlog, true
+}
+
+// parseStreamFilter reads topics (pool, price, loan_health; default all but
+// loan_health unless loans is set) and loans (comma-separated loan ids).
+func parseStreamFilter(c *gin.Context) (service.StreamFilter, error) {
+	f := service.StreamFilter{Topics: map[string]bool{}, Loans: map[uint64]bool{}}
+
+	for _, raw := range splitList(c.Query("loans")) {
+		id, err := strconv.ParseUint(raw, 10, 64)
+		if err != nil {
+			return f, fmt.Errorf("invalid loan id %q", raw)
+		}
+		f.Loans[id] = true
+	}
+	if len(f.Loans) > service.MaxStreamLoans {
+		return f, fmt.Errorf("at most %d loans per stream", service.MaxStreamLoans)
+	}
+
+	topics := splitList(c.Query("topics"))
+	if len(topics) == 0 {
+		topics = []string{model.StreamPool, model.StreamPrice}
+		if len(f.Loans) > 0 {
+			topics = append(topics, model.StreamLoanHealth)
+		}
+	}
+	for _, t := range topics {
+		switch t {
+		case model.StreamPool, model.StreamPrice, model.StreamLoanHealth:
+			f.Topics[t] = true
+		default:
+			return f, fmt.Errorf("unknown topic %q", t)
+		}
+	}
+	if f.Topics[model.StreamLoanHealth] && len(f.Loans) == 0 {
+		return f, fmt.Errorf("loan_health requires loans")
+	}
+	return f, nil
+}
+
+func splitList(raw string) []string {
+	var out []string
+	for _, s := range strings.Split(raw, ",") {
+		if s = strings.TrimSpace(s); s != "" {
+			out = append(out, s)
+		}
+	}
+	return out
+}
+
+// lastEventID reads the Last-Event-ID header sent by reconnecting
+// EventSource clients, or the lastEventId query parameter.
+func lastEventID(c *gin.Context) (uint64, bool) {
+	raw := c.GetHeader("Last-Event-ID")
+	if raw == "" {
+		raw = c.Query("lastEventId")
+	}
+	id, err := strconv.ParseUint(raw, 10, 64)
+	return id, err == nil
+}
+
+// SSE streams events as text/event-stream. Each event carries an id; on
+// reconnect, events after Last-Event-ID are replayed while still buffered,
+// otherwise the latest state of every subscribed topic is sent first.
+func (h *StreamHandler) SSE(c *gin.Context) {
+	client, backlog, ok := h.subscribe(c)
+	if !ok {
+		return
+	}
+	defer h.hub.Unsubscribe(client)
+
+	c.Header("Content-Type", "text/event-stream")
+	c.Header("Cache-Control", "no-cache")
+	c.Header("Connection", "keep-alive")
+	c.Header("X-Accel-Buffering", "no")
+	c.Status(http.StatusOK)
+
+	w := c.Writer
+	write := func(ev *model.StreamEvent) error {
+		data, err := json.Marshal(ev.Data)
+		if err != nil {
+			return err
+		}
+		if _, err := fmt.Fprintf(w, "id: %d\nevent: %s\ndata: %s\n\n", ev.ID, ev.Type, data); err != nil {
+			return err
+		}
+		w.Flush()
+		return nil
+	}
+
+	// ask EventSource to reconnect after 3s.
+	fmt.Fprint(w, "retry: 3000\n\n")
+	w.Flush()
+	for _, ev := range backlog {
+		if err := write(ev); err != nil {
+			return
+		}
+	}
+
+	heartbeat := time.NewTicker(streamHeartbeat)
+	defer heartbeat.Stop()
+	for {
+		select {
+		case <-c.Request.Context().Done():
+			return
+		case <-client.Dropped():
+			return
+		case ev := <-client.Events():
+			if err := write(ev); err != nil {
+				return
+			}
+		case <-heartbeat.C:
+			if _, err := fmt.Fprint(w, ": ping\n\n"); err != nil {
+				return
+			}
+			w.Flush()
+		}
+	}
+}
+
+// WebSocket streams the same events as SSE as JSON text messages
+// ({"id", "type", "time", "data"}); resume with ?lastEventId=.
+func (h *StreamHandler) WebSocket(c *gin.Context) {
+	client, backlog, ok := h.subscribe(c)
+	if !ok {
+		return
+	}
+	defer h.hub.Unsubscribe(client)
+
+	conn, err := h.upgrader.Upgrade(c.Writer, c.Request, nil)
+	if err != nil {
+		// Upgrade has already written an HTTP error.
+		return
+	}
+	defer conn.Close()
+
+	// the read loop only detects close frames and dead peers.
+	closed := make(chan struct{})
+	go func() {
+		defer close(closed)
+		for {
+			if _, _, err := conn.NextReader(); err != nil {
+				return
+			}
+		}
+	}()
+
+	for _, ev := range backlog {
+		if err := conn.WriteJSON(ev); err != nil {
+			return
+		}
+	}
+
+	heartbeat := time.NewTicker(streamHeartbeat)
+	defer heartbeat.Stop()
+	for {
+		select {
+		case <-closed:
+			return
+		case <-client.Dropped():
+			msg := websocket.FormatCloseMessage(websocket.CloseTryAgainLater, "client too slow")
+			if client.Shutdown() {
+				msg = websocket.FormatCloseMessage(websocket.CloseGoingAway, "server shutting down")
+			}
+			conn.WriteControl(websocket.CloseMessage, msg, time.Now().Add(time.Second))
+			return
+		case ev := <-client.Events():
+			if err := conn.WriteJSON(ev); err != nil {
+				return
+			}
+		case <-heartbeat.C:
+			if err := conn.WriteControl(websocket.PingMessage, nil, time.Now().Add(streamHeartbeat)); err != nil {
+				return
+			}
+		}
+	}
+}
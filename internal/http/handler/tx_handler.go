@@ -53,6 +53,16 @@ type withdrawTxRequest struct {
 	FTokenAmount string `json:"fTokenAmount" binding:"required"`
 }
 
+// batchTxRequest is used to build repay-all / liquidate-many batches.
+type batchTxRequest struct {
+	// UserAddress is the wallet that will send the txs; required for repay
+	// batches since every loan must be owned by it.
+	UserAddress string   `json:"userAddress"`
+	LoanIDs     []uint64 `json:"loanIds" binding:"required,min=1"`
+	// Wrap optionally wraps all calls into one tx: "" (none) or "safe" (Safe MultiSend).
+	Wrap string `json:"wrap"`
+}
+
 // mintMockUSDTRequest is used to build a MockUSDT mint tx.
 type mintMockUSDTRequest struct {
 	// To is the recipient address that will receive minted MockUSDT.
@@ -65,13 +75,13 @@ type mintMockUSDTRequest struct {
 func (h *TxHandler) BuildDeposit(c *gin.Context) {
 	var req depositTxRequest
 	if err := c.ShouldBindJSON(&req); err != nil {
-		c.JSON(http.StatusBadRequest, response.Error(4001, err.Error()))
+		c.JSON(http.StatusBadRequest, response.Error(c, 4001, err.Error()))
 		return
 	}
 
 	tx, err := h.txSvc.BuildDepositTx(c.Request.Context(), req.Amount)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, response.Error(1001, err.Error()))
+		c.JSON(http.StatusInternalServerError, response.Error(c, 1001, err.Error()))
 		return
 	}
 
@@ -82,13 +92,13 @@ func (h *TxHandler) BuildDeposit(c *gin.Context) {
 func (h *TxHandler) BuildBorrow(c *gin.Context) {
 	var req borrowTxRequest
 	if err := c.ShouldBindJSON(&req); err != nil {
-		c.JSON(http.StatusBadRequest, response.Error(4001, err.Error()))
+		c.JSON(http.StatusBadRequest, response.Error(c, 4001, err.Error()))
 		return
 	}
 
 	tx, err := h.txSvc.BuildBorrowTx(c.Request.Context(), req.Amount, req.Duration, req.CollateralWei)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, response.Error(1001, err.Error()))
+		c.JSON(http.StatusInternalServerError, response.Error(c, 1001, err.Error()))
 		return
 	}
 
@@ -99,13 +109,13 @@ func (h *TxHandler) BuildBorrow(c *gin.Context) {
 func (h *TxHandler) BuildRepay(c *gin.Context) {
 	var req repayTxRequest
 	if err := c.ShouldBindJSON(&req); err != nil {
-		c.JSON(http.StatusBadRequest, response.Error(4001, err.Error()))
+		c.JSON(http.StatusBadRequest, response.Error(c, 4001, err.Error()))
 		return
 	}
 
 	tx, err := h.txSvc.BuildRepayTx(c.Request.Context(), req.LoanID)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, response.Error(1001, err.Error()))
+		c.JSON(http.StatusInternalServerError, response.Error(c, 1001, err.Error()))
 		return
 	}
 
@@ -116,13 +126,47 @@ func (h *TxHandler) BuildRepay(c *gin.Context) {
 func (h *TxHandler) BuildLiquidate(c *gin.Context) {
 	var req liquidateTxRequest
 	if err := c.ShouldBindJSON(&req); err != nil {
-		c.JSON(http.StatusBadRequest, response.Error(4001, err.Error()))
+		c.JSON(http.StatusBadRequest, response.Error(c, 4001, err.Error()))
 		return
 	}
 
 	tx, err := h.txSvc.BuildLiquidateTx(c.Request.Context(), req.LoanID)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, response.Error(1001, err.Error()))
+		c.JSON(http.StatusInternalServerError, response.Error(c, 1001, err.Error()))
+		return
+	}
+
+	c.JSON(http.StatusOK, response.Success(tx))
+}
+
+// BuildRepayBatch builds a single aggregated approve + one repay per loan.
+func (h *TxHandler) BuildRepayBatch(c *gin.Context) {
+	var req batchTxRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, response.Error(c, 4001, err.Error()))
+		return
+	}
+
+	tx, err := h.txSvc.BuildRepayBatchTx(c.Request.Context(), req.UserAddress, req.LoanIDs, req.Wrap)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, response.Error(c, 1001, err.Error()))
+		return
+	}
+
+	c.JSON(http.StatusOK, response.Success(tx))
+}
+
+// BuildLiquidateBatch builds a single aggregated approve + one liquidate per loan.
+func (h *TxHandler) BuildLiquidateBatch(c *gin.Context) {
+	var req batchTxRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, response.Error(c, 4001, err.Error()))
+		return
+	}
+
+	tx, err := h.txSvc.BuildLiquidateBatchTx(c.Request.Context(), req.UserAddress, req.LoanIDs, req.Wrap)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, response.Error(c, 1001, err.Error()))
 		return
 	}
 
@@ -133,13 +177,13 @@ func (h *TxHandler) BuildLiquidate(c *gin.Context) {
 func (h *TxHandler) BuildWithdraw(c *gin.Context) {
 	var req withdrawTxRequest
 	if err := c.ShouldBindJSON(&req); err != nil {
-		c.JSON(http.StatusBadRequest, response.Error(4001, err.Error()))
+		c.JSON(http.StatusBadRequest, response.Error(c, 4001, err.Error()))
 		return
 	}
 
 	tx, err := h.txSvc.BuildWithdrawTx(c.Request.Context(), req.FTokenAmount)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, response.Error(1001, err.Error()))
+		c.JSON(http.StatusInternalServerError, response.Error(c, 1001, err.Error()))
 		return
 	}
 
@@ -148,16 +192,19 @@ func (h *TxHandler) BuildWithdraw(c *gin.Context) {
 
 // BuildMintMockUSDT builds a single mint(to, amount) tx for MockUSDT on testnet.
 // 前端拿到返回的 TxCall 后，用拥有 mint 权限的钱包（例如 owner）签名并发送。
+//
+// Deprecated: use FaucetHandler.Claim; the route is not registered when the
+// server-side faucet is enabled.
 func (h *TxHandler) BuildMintMockUSDT(c *gin.Context) {
 	var req mintMockUSDTRequest
 	if err := c.ShouldBindJSON(&req); err != nil {
-		c.JSON(http.StatusBadRequest, response.Error(4001, err.Error()))
+		c.JSON(http.StatusBadRequest, response.Error(c, 4001, err.Error()))
 		return
 	}
 
 	tx, err := h.txSvc.BuildMintMockUSDTTx(c.Request.Context(), req.To, req.Amount)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, response.Error(1001, err.Error()))
+		c.JSON(http.StatusInternalServerError, response.Error(c, 1001, err.Error()))
 		return
 	}
 
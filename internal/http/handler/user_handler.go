@@ -2,6 +2,9 @@ package handler
 
 import (
 	"net/http"
+	"strconv"
+	"strings"
+	"time"
 
 	"github.com/cina_dex_backend/internal/service"
 	"github.com/cina_dex_backend/pkg/response"
@@ -10,14 +13,16 @@ import (
 
 // UserHandler exposes user-centric read APIs.
 type UserHandler struct {
-	poolSvc service.PoolService
-	loanSvc service.LoanService
+	poolSvc     service.PoolService
+	loanSvc     service.LoanService
+	activitySvc service.ActivityService
 }
 
-func NewUserHandler(poolSvc service.PoolService, loanSvc service.LoanService) *UserHandler {
+func NewUserHandler(poolSvc service.PoolService, loanSvc service.LoanService, activitySvc service.ActivityService) *UserHandler {
 	return &UserHandler{
-		poolSvc: poolSvc,
-		loanSvc: loanSvc,
+		poolSvc:     poolSvc,
+		loanSvc:     loanSvc,
+		activitySvc: activitySvc,
 	}
 }
 
@@ -25,13 +30,13 @@ func NewUserHandler(poolSvc service.PoolService, loanSvc service.LoanService) *U
 func (h *UserHandler) GetUserPosition(c *gin.Context) {
 	address := c.Param("address")
 	if address == "" {
-		c.JSON(http.StatusBadRequest, response.Error(4001, "address is required"))
+		c.JSON(http.StatusBadRequest, response.Error(c, 4001, "address is required"))
 		return
 	}
 
 	pos, err := h.poolSvc.GetUserPosition(c.Request.Context(), address)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, response.Error(1001, err.Error()))
+		c.JSON(http.StatusInternalServerError, response.Error(c, 1001, err.Error()))
 		return
 	}
 	c.JSON(http.StatusOK, response.Success(pos))
@@ -41,31 +46,99 @@ func (h *UserHandler) GetUserPosition(c *gin.Context) {
 func (h *UserHandler) GetLenderPosition(c *gin.Context) {
 	address := c.Param("address")
 	if address == "" {
-		c.JSON(http.StatusBadRequest, response.Error(4001, "address is required"))
+		c.JSON(http.StatusBadRequest, response.Error(c, 4001, "address is required"))
 		return
 	}
 
 	lp, err := h.poolSvc.GetLenderPosition(c.Request.Context(), address)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, response.Error(1001, err.Error()))
+		c.JSON(http.StatusInternalServerError, response.Error(c, 1001, err.Error()))
 		return
 	}
 
 	c.JSON(http.StatusOK, response.Success(lp))
 }
 
+// GetLenderReport returns a lender's cost basis, earnings and returns over a period.
+// Query: from / to (unix seconds; default from the first deposit to now).
+func (h *UserHandler) GetLenderReport(c *gin.Context) {
+	address := c.Param("address")
+	if address == "" {
+		c.JSON(http.StatusBadRequest, response.Error(c, 4001, "address is required"))
+		return
+	}
+
+	var from, to time.Time
+	if raw := c.Query("from"); raw != "" {
+		ts, err := strconv.ParseInt(raw, 10, 64)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, response.Error(c, 4001, "from must be a unix timestamp"))
+			return
+		}
+		from = time.Unix(ts, 0)
+	}
+	if raw := c.Query("to"); raw != "" {
+		ts, err := strconv.ParseInt(raw, 10, 64)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, response.Error(c, 4001, "to must be a unix timestamp"))
+			return
+		}
+		to = time.Unix(ts, 0)
+	}
+
+	report, err := h.poolSvc.GetLenderReport(c.Request.Context(), address, from, to)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, response.Error(c, 1001, err.Error()))
+		return
+	}
+	c.JSON(http.StatusOK, response.Success(report))
+}
+
 // ListUserLoans returns all loans for a given user address.
 func (h *UserHandler) ListUserLoans(c *gin.Context) {
 	address := c.Param("address")
 	if address == "" {
-		c.JSON(http.StatusBadRequest, response.Error(4001, "address is required"))
+		c.JSON(http.StatusBadRequest, response.Error(c, 4001, "address is required"))
 		return
 	}
 
 	loans, err := h.loanSvc.ListUserLoans(c.Request.Context(), address)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, response.Error(1001, err.Error()))
+		c.JSON(http.StatusInternalServerError, response.Error(c, 1001, err.Error()))
 		return
 	}
 	c.JSON(http.StatusOK, response.Success(loans))
 }
+
+// GetUserHistory returns a user's deposits, withdrawals, borrows, repays,
+// liquidations and faucet mints, newest first.
+// Query: type (comma separated), page (default 1), pageSize (default 20).
+func (h *UserHandler) GetUserHistory(c *gin.Context) {
+	address := c.Param("address")
+	if address == "" {
+		c.JSON(http.StatusBadRequest, response.Error(c, 4001, "address is required"))
+		return
+	}
+
+	page, err := strconv.Atoi(c.DefaultQuery("page", "1"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, response.Error(c, 4001, "invalid page"))
+		return
+	}
+	pageSize, err := strconv.Atoi(c.DefaultQuery("pageSize", "20"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, response.Error(c, 4001, "invalid pageSize"))
+		return
+	}
+	var types []string
+	if raw := c.Query("type"); raw != "" {
+		types = strings.Split(raw, ",")
+	}
+
+	history, err := h.activitySvc.UserHistory(c.Request.Context(), address, types, page, pageSize)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, response.Error(c, 4001, err.Error()))
+		return
+	}
+	c.JSON(http.StatusOK, response.Success(history))
+}
@@ -0,0 +1,112 @@
+package handler
+
+import (
+	"errors"
+	"net/http"
+	"strings"
+
+	"github.com/cina_dex_backend/internal/model"
+	"github.com/cina_dex_backend/internal/service"
+	"github.com/cina_dex_backend/pkg/response"
+	"github.com/gin-gonic/gin"
+)
+
+// WebhookHandler manages loan health webhook subscriptions. Every route on an
+// existing subscription requires "Authorization: Bearer <secret>".
+type WebhookHandler struct {
+	webhookSvc service.WebhookService
+}
+
+func NewWebhookHandler(webhookSvc service.WebhookService) *WebhookHandler {
+	return &WebhookHandler{webhookSvc: webhookSvc}
+}
+
+type webhookSubscribeRequest struct {
+	// URL receives signed JSON POSTs.
+	URL string `json:"url" binding:"required"`
+	// LoanID or Borrower selects the loans to watch; exactly one is required.
+	LoanID   *uint64 `json:"loanId"`
+	Borrower string  `json:"borrower"`
+	// Thresholds are LTV percentages, e.g. [70, 78].
+	Thresholds []float64 `json:"thresholds" binding:"required,min=1"`
+}
+
+// Subscribe registers a webhook and returns it with its signing secret.
+func (h *WebhookHandler) Subscribe(c *gin.Context) {
+	var req webhookSubscribeRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, response.Error(c, 4001, err.Error()))
+		return
+	}
+
+	sub, err := h.webhookSvc.Subscribe(c.Request.Context(), &model.WebhookSubscription{
+		URL:        req.URL,
+		LoanID:     req.LoanID,
+		Borrower:   req.Borrower,
+		Thresholds: req.Thresholds,
+	}, c.ClientIP())
+	if err != nil {
+		h.fail(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, response.Success(sub))
+}
+
+// Get returns a subscription.
+func (h *WebhookHandler) Get(c *gin.Context) {
+	sub, err := h.webhookSvc.Get(c.Param("id"), bearer(c))
+	if err != nil {
+		h.fail(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, response.Success(sub))
+}
+
+// Delete removes a subscription.
+func (h *WebhookHandler) Delete(c *gin.Context) {
+	if err := h.webhookSvc.Unsubscribe(c.Param("id"), bearer(c)); err != nil {
+		h.fail(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, response.Success(nil))
+}
+
+// Deliveries returns recent delivery attempts, newest first.
+func (h *WebhookHandler) Deliveries(c *gin.Context) {
+	list, err := h.webhookSvc.Deliveries(c.Param("id"), bearer(c))
+	if err != nil {
+		h.fail(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, response.Success(list))
+}
+
+// Ping queues a signed test notification.
+func (h *WebhookHandler) Ping(c *gin.Context) {
+	ev, err := h.webhookSvc.Ping(c.Param("id"), bearer(c))
+	if err != nil {
+		h.fail(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, response.Success(ev))
+}
+
+func (h *WebhookHandler) fail(c *gin.Context, err error) {
+	switch {
+	case errors.Is(err, service.ErrInvalidWebhook):
+		c.JSON(http.StatusBadRequest, response.Error(c, 4001, err.Error()))
+	case errors.Is(err, service.ErrWebhookUnauthorized):
+		c.JSON(http.StatusUnauthorized, response.Error(c, 4010, err.Error()))
+	case errors.Is(err, service.ErrWebhookNotFound):
+		c.JSON(http.StatusNotFound, response.Error(c, 4040, err.Error()))
+	case errors.Is(err, service.ErrWebhookLimit):
+		c.JSON(http.StatusTooManyRequests, response.Error(c, 4290, err.Error()))
+	default:
+		c.JSON(http.StatusInternalServerError, response.Error(c, 1001, err.Error()))
+	}
+}
+
+// bearer returns the token of an "Authorization: Bearer <token>" header.
+func bearer(c *gin.Context) string {
+	return strings.TrimPrefix(c.GetHeader("Authorization"), "Bearer ")
+}
@@ -0,0 +1,26 @@
+package http
+
+import (
+	"strconv"
+	"time"
+
+	"github.com/cina_dex_backend/internal/metrics"
+	"github.com/gin-gonic/gin"
+)
+
+// requestMetrics records request counts and latency by route template, so
+// /users/:address/loans is one series rather than one per address.
+func requestMetrics() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		start := time.Now()
+		c.Next()
+
+		route := c.FullPath()
+		if route == "" {
+			route = "unmatched"
+		}
+		method := c.Request.Method
+		metrics.HTTPRequests.WithLabelValues(method, route, strconv.Itoa(c.Writer.Status())).Inc()
+		metrics.HTTPDuration.WithLabelValues(method, route).Observe(time.Since(start).Seconds())
+	}
+}
@@ -1,42 +1,120 @@
 package http
 
 import (
+	"fmt"
+
 	"github.com/cina_dex_backend/internal/config"
 	"github.com/cina_dex_backend/internal/http/handler"
+	"github.com/cina_dex_backend/internal/onchain"
 	"github.com/cina_dex_backend/internal/service"
 	"github.com/gin-gonic/gin"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+	"go.opentelemetry.io/contrib/instrumentation/github.com/gin-gonic/gin/otelgin"
 )
 
+// Services groups the service dependencies of the HTTP layer.
+// Optional services may be nil, in which case their routes are not registered.
+type Services struct {
+	Pool service.PoolService
+	// PoolMetrics serves pool state time series.
+	PoolMetrics service.PoolMetricsService
+	// Revenue serves interest accounting.
+	Revenue service.RevenueService
+	// Solvency serves bad-debt monitoring.
+	Solvency service.SolvencyService
+	// Stress serves price-shock stress tests.
+	Stress service.StressService
+	// Webhooks manages loan health webhook subscriptions.
+	Webhooks service.WebhookService
+	// Stream fans out state changes to SSE / WebSocket clients.
+	Stream *service.StreamHub
+	Loan   service.LoanService
+	// Events is the log indexer; nil when the event signatures could not be
+	// verified, which leaves the routes built on indexed logs unregistered.
+	Events *service.EventIndexer
+	// Activity serves per-user history from indexed logs.
+	Activity service.ActivityService
+	// Export streams CSV / JSON accounting exports.
+	Export service.ExportService
+	Tx     service.TxService
+	Quote  service.QuoteService
+	// PriceHistory serves recorded BNB/USD prices.
+	PriceHistory service.PriceHistoryService
+	// Cache is the shared state cache, read by the health endpoint.
+	Cache *service.StateCache
+	// Readiness backs /health/ready.
+	Readiness service.ReadinessService
+	// RPCCache is the per-user / per-loan read cache, if enabled.
+	RPCCache *onchain.CachedClient
+	// Faucet is only set on testnet when a faucet keystore is configured.
+	Faucet service.FaucetService
+}
+
 // NewRouter wires routes, handlers, and middlewares.
-func NewRouter(cfg *config.Config, poolSvc service.PoolService, loanSvc service.LoanService, txSvc service.TxService, quoteSvc service.QuoteService) *gin.Engine {
+func NewRouter(cfg *config.Config, svcs Services) (*gin.Engine, error) {
 	if cfg.Env == "prod" {
 		gin.SetMode(gin.ReleaseMode)
 	}
 
 	r := gin.New()
-	r.Use(gin.Logger(), gin.Recovery())
+	// only believe X-Forwarded-For from our own proxies, so per-IP limits
+	// cannot be dodged by sending the header.
+	if err := r.SetTrustedProxies(cfg.TrustedProxies); err != nil {
+		return nil, fmt.Errorf("set trusted proxies: %w", err)
+	}
+	// let *gin.Context resolve request context values such as the request ID.
+	r.ContextWithFallback = true
+	r.Use(requestID(), otelgin.Middleware(cfg.Tracing.ServiceName), requestLogger(), recovery(), requestMetrics())
 
-	poolHandler := handler.NewPoolHandler(poolSvc)
-	userHandler := handler.NewUserHandler(poolSvc, loanSvc)
-	loanHandler := handler.NewLoanHandler(loanSvc)
-	txHandler := handler.NewTxHandler(txSvc)
-	quoteHandler := handler.NewQuoteHandler(quoteSvc)
+	poolHandler := handler.NewPoolHandler(svcs.Pool, svcs.PoolMetrics, svcs.Revenue, svcs.Solvency)
+	userHandler := handler.NewUserHandler(svcs.Pool, svcs.Loan, svcs.Activity)
+	loanHandler := handler.NewLoanHandler(svcs.Loan)
+	txHandler := handler.NewTxHandler(svcs.Tx)
+	quoteHandler := handler.NewQuoteHandler(svcs.Quote)
+	riskHandler := handler.NewRiskHandler(svcs.Stress)
+	healthHandler := handler.NewHealthHandler(svcs.Cache, svcs.RPCCache, svcs.Readiness)
+	priceHandler := handler.NewPriceHandler(svcs.PriceHistory)
+	exportHandler := handler.NewExportHandler(svcs.Export)
 
 	api := r.Group("/api/v1")
 	{
-		api.GET("/health", handler.Health)
+		api.GET("/health", healthHandler.Health)
+		api.GET("/health/live", healthHandler.Live)
+		api.GET("/health/ready", healthHandler.Ready)
 
 		api.GET("/pool/state", poolHandler.GetPoolState)
+		api.GET("/pool/metrics/:metric", poolHandler.GetPoolMetric)
+		api.GET("/pool/apy", poolHandler.GetPoolAPY)
+		api.GET("/pool/solvency", poolHandler.GetPoolSolvency)
 
 		api.GET("/users/:address/position", userHandler.GetUserPosition)
 		api.GET("/users/:address/lender-position", userHandler.GetLenderPosition)
 		api.GET("/users/:address/loans", userHandler.ListUserLoans)
 
+		// built on indexed logs, only served once the event signatures are verified
+		if svcs.Events != nil {
+			api.GET("/pool/revenue", poolHandler.GetPoolRevenue)
+			api.GET("/users/:address/lender-report", userHandler.GetLenderReport)
+			api.GET("/users/:address/history", userHandler.GetUserHistory)
+			api.GET("/users/:address/export", exportHandler.ExportUser)
+		}
+
 		api.GET("/loans/:loanId", loanHandler.GetLoan)
 		api.GET("/loans/:loanId/health", loanHandler.GetLoanHealth)
 
+		api.GET("/prices/bnb-usd", priceHandler.GetBnbUsdOHLC)
+
+		// push updates instead of polling /pool/state and /loans/:loanId/health
+		if svcs.Stream != nil {
+			streamHandler := handler.NewStreamHandler(svcs.Stream, svcs.Loan, cfg.Stream.AllowedOrigins)
+			api.GET("/stream", streamHandler.SSE)
+			api.GET("/stream/ws", streamHandler.WebSocket)
+		}
+
 		// risk / quote endpoints
 		api.POST("/borrow/quote", quoteHandler.QuoteBorrow)
+		api.POST("/repay/quote", quoteHandler.QuoteRepay)
+		api.POST("/risk/stress", riskHandler.StressTest)
 
 		// transaction building endpoints
 		api.POST("/tx/deposit", txHandler.BuildDeposit)
@@ -44,13 +122,53 @@ func NewRouter(cfg *config.Config, poolSvc service.PoolService, loanSvc service.
 		api.POST("/tx/borrow", txHandler.BuildBorrow)
 		api.POST("/tx/repay", txHandler.BuildRepay)
 		api.POST("/tx/liquidate", txHandler.BuildLiquidate)
-		// testnet faucet: build MockUSDT mint tx (owner signs & sends on frontend)
-		api.POST("/tx/mock-usdt/mint", txHandler.BuildMintMockUSDT)
+		api.POST("/tx/repay-batch", txHandler.BuildRepayBatch)
+		api.POST("/tx/liquidate-batch", txHandler.BuildLiquidateBatch)
+		// legacy testnet faucet: build MockUSDT mint tx for the owner to sign in
+		// the browser; superseded by /faucet/claim and off when it is enabled.
+		if svcs.Faucet == nil {
+			api.POST("/tx/mock-usdt/mint", txHandler.BuildMintMockUSDT)
+		}
+
+		// loan health webhooks
+		if svcs.Webhooks != nil {
+			webhookHandler := handler.NewWebhookHandler(svcs.Webhooks)
+			api.POST("/webhooks", webhookHandler.Subscribe)
+			api.GET("/webhooks/:id", webhookHandler.Get)
+			api.DELETE("/webhooks/:id", webhookHandler.Delete)
+			api.GET("/webhooks/:id/deliveries", webhookHandler.Deliveries)
+			api.POST("/webhooks/:id/ping", webhookHandler.Ping)
+		}
+
+		// testnet faucet signed server-side
+		if svcs.Faucet != nil {
+			faucetHandler := handler.NewFaucetHandler(svcs.Faucet)
+			api.POST("/faucet/claim", faucetHandler.Claim)
+			api.GET("/faucet/:address", faucetHandler.Status)
+		}
+
+		// admin endpoints, enabled by ADMIN_TOKEN
+		if cfg.AdminToken != "" {
+			admin := api.Group("/admin", adminAuth(cfg.AdminToken))
+			if svcs.Events != nil {
+				admin.GET("/export/events", exportHandler.ExportEvents)
+			}
+			admin.GET("/export/loans", exportHandler.ExportLoans)
+			admin.POST("/pool/solvency/check", poolHandler.CheckPoolSolvency)
+		}
+	}
+
+	// Prometheus metrics, guarded by METRICS_TOKEN when set
+	metricsHandler := gin.WrapH(promhttp.Handler())
+	if cfg.MetricsToken != "" {
+		r.GET("/metrics", adminAuth(cfg.MetricsToken), metricsHandler)
+	} else {
+		r.GET("/metrics", metricsHandler)
 	}
 
 	// Swagger UI & OpenAPI spec
 	r.GET("/swagger", handler.SwaggerUI)
 	r.GET("/swagger/openapi.json", handler.SwaggerSpec)
 
-	return r
+	return r, nil
 }
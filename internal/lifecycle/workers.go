@@ -0,0 +1,74 @@
+// Package lifecycle tracks background workers so the application can stop
+// them and wait for them on shutdown.
+package lifecycle
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+)
+
+// Workers runs named background goroutines and waits for them to return.
+// Workers stop when the context they were started with is cancelled.
+type Workers struct {
+	wg sync.WaitGroup
+
+	mu      sync.Mutex
+	running map[string]int
+}
+
+// NewWorkers constructs an empty Workers.
+func NewWorkers() *Workers {
+	return &Workers{running: make(map[string]int)}
+}
+
+// Go runs fn in a goroutine tracked under name.
+func (w *Workers) Go(name string, fn func()) {
+	w.mu.Lock()
+	w.running[name]++
+	w.mu.Unlock()
+
+	w.wg.Add(1)
+	go func() {
+		defer w.wg.Done()
+		defer func() {
+			w.mu.Lock()
+			if w.running[name]--; w.running[name] <= 0 {
+				delete(w.running, name)
+			}
+			w.mu.Unlock()
+		}()
+		fn()
+	}()
+}
+
+// Wait blocks until every worker returned or ctx is done; in the latter case
+// the error names the workers still running.
+func (w *Workers) Wait(ctx context.Context) error {
+	done := make(chan struct{})
+	go func() {
+		w.wg.Wait()
+		close(done)
+	}()
+
+	select {
+	case <-done:
+		return nil
+	case <-ctx.Done():
+		return fmt.Errorf("workers still running: %s", strings.Join(w.Running(), ", "))
+	}
+}
+
+// Running returns the names of the workers that have not returned yet.
+func (w *Workers) Running() []string {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	names := make([]string, 0, len(w.running))
+	for name := range w.running {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
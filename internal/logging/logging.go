@@ -0,0 +1,55 @@
+// Package logging configures the process-wide slog logger.
+package logging
+
+import (
+	"context"
+	"log/slog"
+	"os"
+
+	"github.com/cina_dex_backend/internal/config"
+	"github.com/cina_dex_backend/pkg/requestid"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// Init installs the default slog logger. The standard log package writes
+// through it too, at info level.
+func Init(cfg config.LogConfig) {
+	opts := &slog.HandlerOptions{Level: cfg.Level}
+	var h slog.Handler
+	if cfg.Format == config.LogJSON {
+		h = slog.NewJSONHandler(os.Stderr, opts)
+	} else {
+		h = slog.NewTextHandler(os.Stderr, opts)
+	}
+	slog.SetDefault(slog.New(contextHandler{h}))
+}
+
+// Fatal logs msg with err at error level and exits.
+func Fatal(msg string, err error) {
+	slog.Error(msg, "err", err)
+	os.Exit(1)
+}
+
+// contextHandler adds the request ID and trace ID carried by the context of
+// each record, so *Context logging calls can be correlated with a request.
+type contextHandler struct {
+	slog.Handler
+}
+
+func (h contextHandler) Handle(ctx context.Context, r slog.Record) error {
+	if id := requestid.FromContext(ctx); id != "" {
+		r.AddAttrs(slog.String("request_id", id))
+	}
+	if sc := trace.SpanContextFromContext(ctx); sc.IsValid() {
+		r.AddAttrs(slog.String("trace_id", sc.TraceID().String()))
+	}
+	return h.Handler.Handle(ctx, r)
+}
+
+func (h contextHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	return contextHandler{h.Handler.WithAttrs(attrs)}
+}
+
+func (h contextHandler) WithGroup(name string) slog.Handler {
+	return contextHandler{h.Handler.WithGroup(name)}
+}
@@ -0,0 +1,174 @@
+// Package metrics defines the Prometheus metrics exported on /metrics.
+package metrics
+
+import (
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+
+	"github.com/cina_dex_backend/internal/model"
+)
+
+const namespace = "cina"
+
+var (
+	// HTTPRequests counts requests by method, route template and status code.
+	HTTPRequests = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Subsystem: "http",
+		Name:      "requests_total",
+		Help:      "HTTP requests by method, route and status code.",
+	}, []string{"method", "route", "status"})
+
+	// HTTPDuration observes request latency by method and route template.
+	HTTPDuration = promauto.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: namespace,
+		Subsystem: "http",
+		Name:      "request_duration_seconds",
+		Help:      "HTTP request latency by method and route.",
+		Buckets:   prometheus.DefBuckets,
+	}, []string{"method", "route"})
+
+	rpcCalls = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Subsystem: "rpc",
+		Name:      "calls_total",
+		Help:      "On-chain RPC calls by JSON-RPC method, contract function and result (ok / error).",
+	}, []string{"method", "function", "result"})
+
+	rpcDuration = promauto.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: namespace,
+		Subsystem: "rpc",
+		Name:      "call_duration_seconds",
+		Help:      "On-chain RPC call latency by JSON-RPC method and contract function.",
+		Buckets:   []float64{.01, .025, .05, .1, .25, .5, 1, 2.5, 5, 10, 30},
+	}, []string{"method", "function"})
+
+	stateCacheLookups = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Subsystem: "state_cache",
+		Name:      "lookups_total",
+		Help:      "State cache lookups by entry (pool_state / price) and result (hit / miss).",
+	}, []string{"entry", "result"})
+
+	jobRuns = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Subsystem: "job",
+		Name:      "runs_total",
+		Help:      "Background job runs by job and result (ok / error).",
+	}, []string{"job", "result"})
+
+	jobLastSuccess = promauto.NewGaugeVec(prometheus.GaugeOpts{
+		Namespace: namespace,
+		Subsystem: "job",
+		Name:      "last_success_timestamp_seconds",
+		Help:      "Unix time of the last successful run of a background job.",
+	}, []string{"job"})
+
+	// PoolTotalAssets is the pool's total assets in USDT.
+	PoolTotalAssets = promauto.NewGauge(prometheus.GaugeOpts{
+		Namespace: namespace,
+		Subsystem: "pool",
+		Name:      "total_assets_usdt",
+		Help:      "LendingPool total assets (TVL) in USDT.",
+	})
+
+	// PoolTotalBorrowed is the pool's outstanding borrows in USDT.
+	PoolTotalBorrowed = promauto.NewGauge(prometheus.GaugeOpts{
+		Namespace: namespace,
+		Subsystem: "pool",
+		Name:      "total_borrowed_usdt",
+		Help:      "LendingPool total borrowed in USDT.",
+	})
+
+	// PoolAvailableLiquidity is the USDT available to borrow or withdraw.
+	PoolAvailableLiquidity = promauto.NewGauge(prometheus.GaugeOpts{
+		Namespace: namespace,
+		Subsystem: "pool",
+		Name:      "available_liquidity_usdt",
+		Help:      "LendingPool available liquidity in USDT.",
+	})
+
+	// PoolUtilization is total borrowed / total assets, between 0 and 1.
+	PoolUtilization = promauto.NewGauge(prometheus.GaugeOpts{
+		Namespace: namespace,
+		Subsystem: "pool",
+		Name:      "utilization_ratio",
+		Help:      "LendingPool utilization (total borrowed / total assets).",
+	})
+
+	// NativePrice is the validated BNB/USD price.
+	NativePrice = promauto.NewGauge(prometheus.GaugeOpts{
+		Namespace: namespace,
+		Subsystem: "price",
+		Name:      "bnb_usd",
+		Help:      "Validated BNB/USD price.",
+	})
+
+	// PriceUnsafe is 1 while the validated price is flagged unsafe.
+	PriceUnsafe = promauto.NewGauge(prometheus.GaugeOpts{
+		Namespace: namespace,
+		Subsystem: "price",
+		Name:      "unsafe",
+		Help:      "1 while the BNB/USD price is flagged unsafe (stale, deviating or too few sources).",
+	})
+)
+
+func result(err error) string {
+	if err != nil {
+		return "error"
+	}
+	return "ok"
+}
+
+// ObserveRPC records an RPC call started at start. function is the contract
+// function for eth_call and empty otherwise.
+func ObserveRPC(method, function string, start time.Time, err error) {
+	rpcCalls.WithLabelValues(method, function, result(err)).Inc()
+	rpcDuration.WithLabelValues(method, function).Observe(time.Since(start).Seconds())
+}
+
+// StateCacheLookup records a state cache read.
+func StateCacheLookup(entry string, hit bool) {
+	res := "miss"
+	if hit {
+		res = "hit"
+	}
+	stateCacheLookups.WithLabelValues(entry, res).Inc()
+}
+
+// JobDone records a background job run.
+func JobDone(job string, err error) {
+	jobRuns.WithLabelValues(job, result(err)).Inc()
+	if err == nil {
+		jobLastSuccess.WithLabelValues(job).SetToCurrentTime()
+	}
+}
+
+// RegisterRPCCache exports the counters of the per-user / per-loan RPC read
+// cache; stats is called on every scrape.
+func RegisterRPCCache(stats func() *model.RPCCacheStats) {
+	counter := func(name, help string, v func(*model.RPCCacheStats) float64) {
+		promauto.NewCounterFunc(prometheus.CounterOpts{
+			Namespace: namespace,
+			Subsystem: "rpc_cache",
+			Name:      name,
+			Help:      help,
+		}, func() float64 { return v(stats()) })
+	}
+	counter("hits_total", "RPC cache lookups served from the cache.",
+		func(s *model.RPCCacheStats) float64 { return float64(s.Hits) })
+	counter("misses_total", "RPC cache lookups that read from the RPC.",
+		func(s *model.RPCCacheStats) float64 { return float64(s.Misses) })
+	counter("coalesced_total", "RPC cache lookups that shared another caller's in-flight read.",
+		func(s *model.RPCCacheStats) float64 { return float64(s.Coalesced) })
+	counter("evictions_total", "RPC cache entries evicted by the size bound.",
+		func(s *model.RPCCacheStats) float64 { return float64(s.Evictions) })
+	promauto.NewGaugeFunc(prometheus.GaugeOpts{
+		Namespace: namespace,
+		Subsystem: "rpc_cache",
+		Name:      "entries",
+		Help:      "Entries held in the RPC cache.",
+	}, func() float64 { return float64(stats().Entries) })
+}
@@ -8,6 +8,391 @@ type PoolState struct {
 	AvailableLiquidity string `json:"availableLiquidity"`
 	ExchangeRate       string `json:"exchangeRate"`
 	TotalFTokenSupply  string `json:"totalFTokenSupply"`
+	// FetchedAt (unix seconds) and BlockNumber describe when a live or
+	// cached state was read; both are omitted for historical reads.
+	FetchedAt   int64  `json:"fetchedAt,omitempty"`
+	BlockNumber uint64 `json:"blockNumber,omitempty"`
+}
+
+// CacheStatus describes the age of a value held in the state cache.
+type CacheStatus struct {
+	FetchedAt   int64  `json:"fetchedAt"`
+	BlockNumber uint64 `json:"blockNumber,omitempty"`
+	AgeSeconds  int64  `json:"ageSeconds"`
+	// Expired is set once the value is older than the cache max age; it is
+	// then no longer served and callers read live instead.
+	Expired bool `json:"expired"`
+}
+
+// Component health statuses, from best to worst.
+const (
+	HealthOK       = "ok"
+	HealthDegraded = "degraded"
+	HealthDown     = "down"
+)
+
+// ComponentStatus is the health of one dependency or background worker.
+type ComponentStatus struct {
+	Name   string `json:"name"`
+	Status string `json:"status"`
+	// Message explains a degraded or down status.
+	Message   string                 `json:"message,omitempty"`
+	LatencyMs int64                  `json:"latencyMs,omitempty"`
+	Details   map[string]interface{} `json:"details,omitempty"`
+}
+
+// Readiness is the result of the readiness checks. Status is the worst
+// component status; the service is not ready when it is down.
+type Readiness struct {
+	Status     string             `json:"status"`
+	Ready      bool               `json:"ready"`
+	CheckedAt  int64              `json:"checkedAt"`
+	Components []*ComponentStatus `json:"components"`
+}
+
+// RPCCacheStats reports the read-through cache of per-user / per-loan RPC reads.
+type RPCCacheStats struct {
+	Hits uint64 `json:"hits"`
+	// Misses counts RPC reads made; Coalesced counts lookups that missed but
+	// shared another caller's in-flight read.
+	Misses     uint64  `json:"misses"`
+	Coalesced  uint64  `json:"coalesced"`
+	Evictions  uint64  `json:"evictions"`
+	Entries    int     `json:"entries"`
+	Capacity   int     `json:"capacity"`
+	TTLSeconds float64 `json:"ttlSeconds"`
+	// HeadBlock is the latest block seen; older entries are not served.
+	HeadBlock uint64 `json:"headBlock"`
+}
+
+// BlockRef identifies a block by number and timestamp (unix seconds).
+type BlockRef struct {
+	Number uint64 `json:"number"`
+	Time   uint64 `json:"time"`
+}
+
+// PoolSnapshot is a PoolState read at a specific block, persisted for charts.
+type PoolSnapshot struct {
+	BlockNumber uint64    `json:"blockNumber"`
+	BlockTime   uint64    `json:"blockTime"`
+	State       PoolState `json:"state"`
+	// Utilization is TotalBorrowed / TotalAssets with 18 decimals (1e18 = 100%).
+	Utilization string `json:"utilization"`
+}
+
+// MetricPoint is one value of a pool metric time series.
+type MetricPoint struct {
+	// Time is the block time of the snapshot (unix seconds).
+	Time        int64  `json:"time"`
+	BlockNumber uint64 `json:"blockNumber"`
+	Value       string `json:"value"`
+}
+
+// PoolMetricSeries is a downsampled time series of a single pool metric.
+type PoolMetricSeries struct {
+	// Metric is one of tvl, borrowed, available-liquidity, exchange-rate, utilization.
+	Metric   string         `json:"metric"`
+	Interval string         `json:"interval"`
+	From     int64          `json:"from"`
+	To       int64          `json:"to"`
+	Points   []*MetricPoint `json:"points"`
+}
+
+// TrailingAPY is the supply yield realized over a trailing window, derived
+// from FToken exchange-rate growth.
+type TrailingAPY struct {
+	// Window is the requested lookback, e.g. "7d".
+	Window string `json:"window"`
+	// Available is false when there is not enough history yet.
+	Available bool `json:"available"`
+	// Partial is true when history is shorter than Window; the yield is
+	// annualized over SpanSeconds instead.
+	Partial          bool   `json:"partial"`
+	SpanSeconds      int64  `json:"spanSeconds,omitempty"`
+	FromTime         int64  `json:"fromTime,omitempty"`
+	FromBlock        uint64 `json:"fromBlock,omitempty"`
+	FromExchangeRate string `json:"fromExchangeRate,omitempty"`
+	// AprPercent is the simple annualized growth, ApyPercent the compounded one.
+	AprPercent string `json:"aprPercent,omitempty"`
+	ApyPercent string `json:"apyPercent,omitempty"`
+}
+
+// ForwardAPY estimates the supply yield from the fixed interest of loans
+// currently outstanding.
+type ForwardAPY struct {
+	ActiveLoans        int    `json:"activeLoans"`
+	TotalAssets        string `json:"totalAssets"`
+	TotalBorrowed      string `json:"totalBorrowed"`
+	Utilization        string `json:"utilization"`
+	UtilizationPercent string `json:"utilizationPercent"`
+	// AnnualInterest is the yearly interest (USDT, 6 decimals) of the active loans.
+	AnnualInterest string `json:"annualInterest"`
+	// BorrowAprPercent is the principal-weighted fixed rate of the active loans.
+	BorrowAprPercent string `json:"borrowAprPercent"`
+	// SupplyAprPercent is AnnualInterest / TotalAssets.
+	SupplyAprPercent string `json:"supplyAprPercent"`
+}
+
+// PoolAPY reports trailing realized and forward estimated supply yields.
+type PoolAPY struct {
+	ExchangeRate string         `json:"exchangeRate"`
+	AsOf         int64          `json:"asOf"`
+	Trailing     []*TrailingAPY `json:"trailing"`
+	Forward      *ForwardAPY    `json:"forward,omitempty"`
+	Warnings     []string       `json:"warnings,omitempty"`
+}
+
+// Activity event types decoded from LendingPool / MockUSDT logs.
+const (
+	ActivityDeposit    = "deposit"
+	ActivityWithdraw   = "withdraw"
+	ActivityBorrow     = "borrow"
+	ActivityRepay      = "repay"
+	ActivityLiquidate  = "liquidate"
+	ActivityFaucetMint = "faucet-mint"
+)
+
+// ActivityEvent is one user-facing protocol action taken from a log.
+// Amount is in USDT smallest units (6 decimals) for every type.
+type ActivityEvent struct {
+	Type        string `json:"type"`
+	BlockNumber uint64 `json:"blockNumber"`
+	BlockTime   uint64 `json:"blockTime"`
+	TxHash      string `json:"txHash"`
+	LogIndex    uint   `json:"logIndex"`
+	// Account is the depositor / withdrawer / borrower / mint recipient.
+	Account string `json:"account"`
+	// Liquidator is set for liquidate events.
+	Liquidator string  `json:"liquidator,omitempty"`
+	LoanID     *uint64 `json:"loanId,omitempty"`
+	Amount     string  `json:"amount"`
+	// FTokenAmount is the FToken minted (deposit) or burned (withdraw), 18 decimals.
+	FTokenAmount string `json:"fTokenAmount,omitempty"`
+	// CollateralAmount is the BNB locked (borrow) or seized (liquidate), in wei.
+	CollateralAmount string `json:"collateralAmount,omitempty"`
+	// RepaymentAmount is the amount due at maturity (borrow only).
+	RepaymentAmount string `json:"repaymentAmount,omitempty"`
+	Duration        uint64 `json:"duration,omitempty"`
+	// Role is the queried user's side of the event: "borrower" or "liquidator".
+	Role string `json:"role,omitempty"`
+}
+
+// ActivityPage is one page of a user's activity history, newest first.
+type ActivityPage struct {
+	Address  string           `json:"address"`
+	Items    []*ActivityEvent `json:"items"`
+	Total    int              `json:"total"`
+	Page     int              `json:"page"`
+	PageSize int              `json:"pageSize"`
+	// IndexedBlock is the last block covered by the indexer; newer activity is not listed yet.
+	IndexedBlock uint64 `json:"indexedBlock"`
+}
+
+// RevenueDay is the interest accounting of one UTC day. Amounts are in USDT
+// smallest units (6 decimals).
+type RevenueDay struct {
+	Date                string `json:"date"` // YYYY-MM-DD (UTC)
+	Repays              int    `json:"repays"`
+	Liquidations        int    `json:"liquidations"`
+	RepayInterest       string `json:"repayInterest"`
+	LiquidationInterest string `json:"liquidationInterest"`
+	Interest            string `json:"interest"`
+	// LiquidationShortfall is debt not covered by the seized collateral's value.
+	LiquidationShortfall string `json:"liquidationShortfall"`
+	CumulativeInterest   string `json:"cumulativeInterest"`
+	CumulativeShortfall  string `json:"cumulativeShortfall"`
+}
+
+// OutstandingInterest describes interest on loans that are still active.
+type OutstandingInterest struct {
+	ActiveLoans int    `json:"activeLoans"`
+	Principal   string `json:"principal"`
+	// ScheduledInterest is the fixed interest due at maturity on all active loans.
+	ScheduledInterest string `json:"scheduledInterest"`
+	// AccruedInterest accrues ScheduledInterest linearly from start to maturity.
+	AccruedInterest string `json:"accruedInterest"`
+	// UnderwaterLoans / UnderwaterDebt count loans whose debt exceeds the
+	// current collateral value, and the uncovered part of their debt.
+	UnderwaterLoans int    `json:"underwaterLoans"`
+	UnderwaterDebt  string `json:"underwaterDebt"`
+}
+
+// PoolRevenue reports interest earned by the pool over [From, To].
+type PoolRevenue struct {
+	From                 int64  `json:"from"`
+	To                   int64  `json:"to"`
+	RepayInterest        string `json:"repayInterest"`
+	LiquidationInterest  string `json:"liquidationInterest"`
+	TotalInterest        string `json:"totalInterest"`
+	LiquidationShortfall string `json:"liquidationShortfall"`
+	// LiquidatorBonus is the collateral value paid to liquidators above the debt they repaid.
+	LiquidatorBonus string               `json:"liquidatorBonus"`
+	Outstanding     *OutstandingInterest `json:"outstanding,omitempty"`
+	Days            []*RevenueDay        `json:"days"`
+	IndexedBlock    uint64               `json:"indexedBlock"`
+	Warnings        []string             `json:"warnings,omitempty"`
+}
+
+// Solvency statuses.
+const (
+	SolvencyOK       = "ok"
+	SolvencyWarning  = "warning"
+	SolvencyCritical = "critical"
+)
+
+// SolvencyLoan is an active loan valued at the current oracle price.
+// Debt and values are in USDT smallest units (6 decimals).
+type SolvencyLoan struct {
+	LoanID          uint64 `json:"loanId"`
+	Borrower        string `json:"borrower"`
+	Debt            string `json:"debt"`
+	Collateral      string `json:"collateral"`
+	CollateralValue string `json:"collateralValue"`
+	LTV             string `json:"ltv"`
+	LtvPercent      string `json:"ltvPercent"`
+	// Liquidatable is true above the liquidation threshold.
+	Liquidatable bool `json:"liquidatable"`
+	// BelowBonus is true when the collateral no longer covers debt plus the
+	// liquidation bonus, so liquidating it is not fully profitable.
+	BelowBonus bool `json:"belowBonus"`
+	// Shortfall is debt minus collateral value when positive (bad debt).
+	Shortfall string `json:"shortfall"`
+}
+
+// SolvencyReport values all active loans and aggregates bad debt against the pool.
+type SolvencyReport struct {
+	Status      string `json:"status"`
+	CheckedAt   int64  `json:"checkedAt"`
+	Price       string `json:"price"`
+	PriceUnsafe bool   `json:"priceUnsafe"`
+
+	ActiveLoans          int    `json:"activeLoans"`
+	TotalDebt            string `json:"totalDebt"`
+	TotalCollateralValue string `json:"totalCollateralValue"`
+	TotalAssets          string `json:"totalAssets"`
+
+	LiquidatableLoans int    `json:"liquidatableLoans"`
+	BelowBonusLoans   int    `json:"belowBonusLoans"`
+	BadDebtLoans      int    `json:"badDebtLoans"`
+	BadDebt           string `json:"badDebt"`
+	// ShortfallBps is BadDebt / TotalAssets in basis points.
+	ShortfallBps string `json:"shortfallBps"`
+
+	// AtRisk lists the riskiest loans (highest LTV first).
+	AtRisk   []*SolvencyLoan `json:"atRisk"`
+	Warnings []string        `json:"warnings,omitempty"`
+}
+
+// StressScenario is the outcome of revaluing all active loans at one
+// hypothetical BNB/USD price. Amounts are USDT smallest units (6 decimals)
+// unless noted.
+type StressScenario struct {
+	// Price is the hypothetical BNB/USD price, 18 decimals.
+	Price string `json:"price"`
+	// ChangePercent is Price relative to the current price, e.g. "-30.00".
+	ChangePercent string `json:"changePercent"`
+
+	LiquidatableLoans int `json:"liquidatableLoans"`
+	// NewlyLiquidatable counts loans that are not liquidatable at the current price.
+	NewlyLiquidatable int `json:"newlyLiquidatable"`
+	// DebtAtRisk is the debt of liquidatable loans.
+	DebtAtRisk string `json:"debtAtRisk"`
+	// CollateralAtRisk is the collateral (wei) of liquidatable loans.
+	CollateralAtRisk string `json:"collateralAtRisk"`
+	// LiquidatorPayout is the collateral (wei) liquidators would seize from
+	// liquidatable loans whose collateral still covers the debt: debt value *
+	// liquidation bonus, capped at the loan's collateral.
+	LiquidatorPayout      string `json:"liquidatorPayout"`
+	LiquidatorPayoutValue string `json:"liquidatorPayoutValue"`
+	// LiquidatorProfit is LiquidatorPayoutValue minus the debt repaid for it.
+	LiquidatorProfit string `json:"liquidatorProfit"`
+
+	BadDebtLoans int    `json:"badDebtLoans"`
+	BadDebt      string `json:"badDebt"`
+	// ShortfallBps is BadDebt / TotalAssets in basis points.
+	ShortfallBps string `json:"shortfallBps"`
+}
+
+// StressTest compares several price scenarios against the current state.
+type StressTest struct {
+	CheckedAt    int64  `json:"checkedAt"`
+	CurrentPrice string `json:"currentPrice"`
+	ActiveLoans  int    `json:"activeLoans"`
+	TotalDebt    string `json:"totalDebt"`
+	TotalAssets  string `json:"totalAssets"`
+	// Current is the scenario at the current price, as a baseline.
+	Current   *StressScenario   `json:"current"`
+	Scenarios []*StressScenario `json:"scenarios"`
+	Warnings  []string          `json:"warnings,omitempty"`
+}
+
+// Alert is a notification raised by a background monitor.
+type Alert struct {
+	Source    string      `json:"source"`
+	Level     string      `json:"level"`
+	Message   string      `json:"message"`
+	CreatedAt int64       `json:"createdAt"`
+	Data      interface{} `json:"data,omitempty"`
+}
+
+// WebhookSubscription asks for a notification when a loan's LTV crosses one
+// of Thresholds. Exactly one of LoanID and Borrower is set; a borrower
+// subscription covers all of that borrower's active loans.
+type WebhookSubscription struct {
+	ID       string  `json:"id"`
+	URL      string  `json:"url"`
+	LoanID   *uint64 `json:"loanId,omitempty"`
+	Borrower string  `json:"borrower,omitempty"`
+	// Thresholds are LTV percentages in ascending order, e.g. [70, 78].
+	Thresholds []float64 `json:"thresholds"`
+	CreatedAt  int64     `json:"createdAt"`
+	// Secret signs notifications (HMAC-SHA256); only returned on creation.
+	Secret string `json:"secret,omitempty"`
+}
+
+// Webhook event types.
+const (
+	WebhookEventThreshold = "loan.ltv_threshold"
+	WebhookEventPing      = "ping"
+)
+
+// WebhookEvent is the JSON body POSTed to a subscription's URL.
+type WebhookEvent struct {
+	ID             string `json:"id"`
+	Type           string `json:"type"`
+	SubscriptionID string `json:"subscriptionId"`
+	CreatedAt      int64  `json:"createdAt"`
+
+	LoanID   uint64 `json:"loanId,omitempty"`
+	Borrower string `json:"borrower,omitempty"`
+	// Threshold is the highest threshold (percent) the LTV is now above.
+	Threshold      float64 `json:"threshold,omitempty"`
+	LTV            string  `json:"ltv,omitempty"`
+	LtvPercent     string  `json:"ltvPercent,omitempty"`
+	IsLiquidatable bool    `json:"isLiquidatable"`
+	// Price is the BNB/USD price (18 decimals) of the refresh that triggered it.
+	Price string `json:"price,omitempty"`
+}
+
+// Webhook delivery statuses.
+const (
+	DeliverySucceeded = "succeeded"
+	DeliveryRetrying  = "retrying"
+	DeliveryFailed    = "failed"
+)
+
+// WebhookDelivery records one attempt to deliver a WebhookEvent.
+type WebhookDelivery struct {
+	SubscriptionID string `json:"subscriptionId"`
+	EventID        string `json:"eventId"`
+	EventType      string `json:"eventType"`
+	Attempt        int    `json:"attempt"`
+	Status         string `json:"status"`
+	StatusCode     int    `json:"statusCode,omitempty"`
+	Error          string `json:"error,omitempty"`
+	// DurationMs is the request round trip in milliseconds.
+	DurationMs int64 `json:"durationMs"`
+	At         int64 `json:"at"`
 }
 
 // Loan represents a single on-chain loan position.
@@ -28,6 +413,30 @@ type LoanHealth struct {
 	IsLiquidatable bool   `json:"isLiquidatable"`
 }
 
+// Stream event types.
+const (
+	StreamPool       = "pool"
+	StreamPrice      = "price"
+	StreamLoanHealth = "loan_health"
+)
+
+// StreamEvent is one server-sent event. Data is a *PoolState, *PriceInfo or
+// *LoanHealthUpdate depending on Type.
+type StreamEvent struct {
+	ID     uint64      `json:"id"`
+	Type   string      `json:"type"`
+	LoanID uint64      `json:"-"`
+	Time   int64       `json:"time"`
+	Data   interface{} `json:"data"`
+}
+
+// LoanHealthUpdate is a loan's getLoanHealth result pushed to stream clients.
+type LoanHealthUpdate struct {
+	LoanID         uint64 `json:"loanId"`
+	LTV            string `json:"ltv"`
+	IsLiquidatable bool   `json:"isLiquidatable"`
+}
+
 // UserPosition mirrors LendingPool.getUserPosition().
 type UserPosition struct {
 	Address         string   `json:"address"`
@@ -67,6 +476,28 @@ type LiquidateTx struct {
 	Liquidate *TxCall `json:"liquidate"`
 }
 
+// SafeTx is a Safe wallet transaction that executes several calls at once
+// through the MultiSendCallOnly contract. Operation is 1 (DELEGATECALL) as
+// required by MultiSend.
+type SafeTx struct {
+	To        string `json:"to"`
+	Data      string `json:"data"`
+	Value     string `json:"value"`
+	Operation uint8  `json:"operation"`
+}
+
+// BatchTx bundles a single aggregated approve with one repay/liquidate call
+// per loan, in execution order.
+type BatchTx struct {
+	LoanIDs []uint64 `json:"loanIds"`
+	// TotalAmount is the USDT (6 decimals) pulled by all calls together.
+	TotalAmount string    `json:"totalAmount"`
+	Approve     *TxCall   `json:"approve"`
+	Calls       []*TxCall `json:"calls"`
+	// Safe wraps approve + calls into one MultiSend tx; only set when requested.
+	Safe *SafeTx `json:"safe,omitempty"`
+}
+
 // WithdrawTx contains the single withdraw call for LP redemptions.
 type WithdrawTx struct {
 	Withdraw *TxCall `json:"withdraw"`
@@ -84,6 +515,118 @@ type LenderPosition struct {
 	NetDeposited string `json:"netDeposited"`
 	// Interest is the current realized interest = underlyingBalance - netDeposited.
 	Interest string `json:"interest"`
+
+	// Accounting from indexed deposit/withdraw events (average cost method).
+	TotalDeposited string `json:"totalDeposited"`
+	TotalWithdrawn string `json:"totalWithdrawn"`
+	// CostBasis is the USDT cost of the FToken still held.
+	CostBasis string `json:"costBasis"`
+	// AvgEntryExchangeRate is CostBasis per FToken, 18 decimals like ExchangeRate.
+	AvgEntryExchangeRate string `json:"avgEntryExchangeRate"`
+	// RealizedEarnings is withdrawal proceeds minus the cost of the FToken burned.
+	RealizedEarnings string `json:"realizedEarnings"`
+	// UnrealizedEarnings is UnderlyingBalance minus CostBasis.
+	UnrealizedEarnings string `json:"unrealizedEarnings"`
+	// HistoryComplete is false when indexed events do not explain the FToken
+	// balance (FToken transfers, or activity before the indexer start block).
+	HistoryComplete bool     `json:"historyComplete"`
+	Warnings        []string `json:"warnings,omitempty"`
+}
+
+// LenderPeriodReturn is a lender's performance over [From, To].
+type LenderPeriodReturn struct {
+	From              int64  `json:"from"`
+	To                int64  `json:"to"`
+	StartExchangeRate string `json:"startExchangeRate"`
+	EndExchangeRate   string `json:"endExchangeRate"`
+	StartFToken       string `json:"startFToken"`
+	EndFToken         string `json:"endFToken"`
+	StartValue        string `json:"startValue"`
+	EndValue          string `json:"endValue"`
+	Deposits          string `json:"deposits"`
+	Withdrawals       string `json:"withdrawals"`
+	// Earnings = EndValue - StartValue - Deposits + Withdrawals.
+	Earnings string `json:"earnings"`
+	// TwrPercent is the time-weighted return, i.e. FToken exchange-rate growth.
+	TwrPercent           string `json:"twrPercent"`
+	TwrAnnualizedPercent string `json:"twrAnnualizedPercent,omitempty"`
+	// MwrPercent is the money-weighted return (modified Dietz) over the period,
+	// MwrAnnualizedPercent the internal rate of return of the cash flows (XIRR).
+	MwrPercent           string `json:"mwrPercent,omitempty"`
+	MwrAnnualizedPercent string `json:"mwrAnnualizedPercent,omitempty"`
+}
+
+// LenderReport combines a lender's position with period returns and the
+// deposit/withdraw flows of the period.
+type LenderReport struct {
+	Position *LenderPosition     `json:"position"`
+	Period   *LenderPeriodReturn `json:"period"`
+	Flows    []*ActivityEvent    `json:"flows"`
+}
+
+// PriceRound mirrors AggregatorV3Interface.latestRoundData() of the raw price feed.
+type PriceRound struct {
+	RoundID string `json:"roundId"`
+	// Answer is the raw feed answer with Decimals decimals.
+	Answer   string `json:"answer"`
+	Decimals uint8  `json:"decimals"`
+	// Price is Answer scaled to 18 decimals.
+	Price           string `json:"price"`
+	StartedAt       uint64 `json:"startedAt"`
+	UpdatedAt       uint64 `json:"updatedAt"`
+	AnsweredInRound string `json:"answeredInRound"`
+}
+
+// PriceSample is a BNB/USD price reported by a single price source.
+type PriceSample struct {
+	// Source names the provider, e.g. "oracle", "chainlink", "dex-twap".
+	Source string `json:"source"`
+	// Price is the BNB/USD price with 18 decimals; empty if the source failed.
+	Price string `json:"price,omitempty"`
+	// UpdatedAt is when the source last updated (unix seconds), if known.
+	UpdatedAt uint64 `json:"updatedAt,omitempty"`
+	// RoundID is only set for round-based feeds.
+	RoundID string `json:"roundId,omitempty"`
+	// Stale marks a sample excluded from aggregation (failed or outdated).
+	Stale bool `json:"stale"`
+	// DeviationBps is the gap to the served price (the oracle, or the median
+	// without it), in basis points.
+	DeviationBps string   `json:"deviationBps,omitempty"`
+	Warnings     []string `json:"warnings,omitempty"`
+}
+
+// PriceInfo is the aggregated BNB/USD price used by quotes, together with
+// the per-source samples and the checks performed on them.
+type PriceInfo struct {
+	// Price is the price the contract enforces, 18 decimals: OraclePrice, or
+	// MedianPrice (flagged Unsafe) when the oracle could not be read.
+	Price string `json:"price"`
+	// MedianPrice is the median of all valid sources, the fallback Price.
+	MedianPrice string `json:"medianPrice"`
+	// OraclePrice is ChainlinkOracle.getPrice(address(0)), the value the contract uses.
+	OraclePrice string `json:"oraclePrice,omitempty"`
+	// FeedPrice is the raw feed answer scaled to 18 decimals; empty if no feed is configured.
+	FeedPrice string `json:"feedPrice,omitempty"`
+	RoundID   string `json:"roundId,omitempty"`
+	// UpdatedAt is the feed's updatedAt (unix seconds).
+	UpdatedAt uint64 `json:"updatedAt,omitempty"`
+	// DeviationBps is the largest gap between a valid source and the served
+	// price, in basis points.
+	DeviationBps string `json:"deviationBps,omitempty"`
+	// Stale is set when the raw feed round is older than the heartbeat or otherwise invalid.
+	Stale bool `json:"stale"`
+	// Deviating is set when sources disagree beyond the allowed deviation.
+	Deviating bool `json:"deviating"`
+	// Unsafe is set when the price should not be relied upon: stale, deviating,
+	// backed by fewer valid sources than required, or not the oracle's.
+	Unsafe  bool           `json:"unsafe"`
+	Sources []*PriceSample `json:"sources"`
+	// Warnings explains why Stale / Deviating / Unsafe are set.
+	Warnings []string `json:"warnings,omitempty"`
+	// CheckedAt is the unix time (seconds) the checks ran.
+	CheckedAt int64 `json:"checkedAt"`
+	// BlockNumber is the head block when the price was read; 0 if unknown.
+	BlockNumber uint64 `json:"blockNumber,omitempty"`
 }
 
 // BorrowQuote describes the required collateral for a desired borrow amount.
@@ -97,4 +640,131 @@ type BorrowQuote struct {
 	BnbUsdPrice string `json:"bnbUsdPrice"`
 	// MaxLTVPercent is the max LTV used in this quote, e.g. "75" for 75%.
 	MaxLTVPercent string `json:"maxLtvPercent"`
+	// PriceStale / PriceDeviating flag a price that failed feed validation;
+	// quotes built on such a price should not be trusted.
+	PriceStale     bool `json:"priceStale"`
+	PriceDeviating bool `json:"priceDeviating"`
+	// PriceUnsafe is set when price sources disagree or too few are available.
+	PriceUnsafe bool `json:"priceUnsafe"`
+	// PriceCheckedAt is when the price was read (unix seconds).
+	PriceCheckedAt int64 `json:"priceCheckedAt"`
+	// LiquidationThresholdPercent is the LTV above which the loan can be liquidated, e.g. "80".
+	LiquidationThresholdPercent string `json:"liquidationThresholdPercent"`
+
+	// Loan terms, only set when a duration is given.
+	// Duration is the requested loan duration in seconds.
+	Duration uint64 `json:"duration,omitempty"`
+	// InterestRateBps is the fixed annual rate used, in basis points.
+	InterestRateBps string `json:"interestRateBps,omitempty"`
+	// Interest is the expected interest in USDT smallest units (6 decimals).
+	Interest string `json:"interest,omitempty"`
+	// RepaymentAmount is principal + interest, as the contract will record it.
+	RepaymentAmount string `json:"repaymentAmount,omitempty"`
+	// AprPercent is the effective APR, e.g. "10.00".
+	AprPercent string `json:"aprPercent,omitempty"`
+	// MaturityTime is now + duration (unix seconds), assuming the borrow is mined now.
+	MaturityTime uint64 `json:"maturityTime,omitempty"`
+
+	// InitialLTV is the LTV right after borrowing with CollateralWei, 18 decimals,
+	// computed on the repayment amount like getLoanHealth.
+	InitialLTV        string `json:"initialLtv,omitempty"`
+	InitialLtvPercent string `json:"initialLtvPercent,omitempty"`
+	// LiquidationPrice is the BNB/USD price (18 decimals) at which the loan becomes liquidatable.
+	LiquidationPrice string `json:"liquidationPrice"`
+	// AvailableLiquidity is the pool's current available USDT (6 decimals).
+	AvailableLiquidity string `json:"availableLiquidity"`
+	// LiquiditySufficient reports whether AvailableLiquidity covers the principal.
+	LiquiditySufficient bool `json:"liquiditySufficient"`
+}
+
+// LoanRepayQuote breaks down the repayment of a single loan.
+type LoanRepayQuote struct {
+	LoanID uint64 `json:"loanId"`
+	// Principal is the borrowed USDT amount (6 decimals).
+	Principal string `json:"principal"`
+	// Interest is repaymentAmount - principal (6 decimals).
+	Interest string `json:"interest"`
+	// RepaymentAmount is the total USDT due on repay, as stored on-chain (6 decimals).
+	RepaymentAmount string `json:"repaymentAmount"`
+	// AprPercent is the effective APR implied by repaymentAmount/principal/duration, e.g. "10.00".
+	AprPercent string `json:"aprPercent"`
+	// CollateralReturned is the BNB collateral (wei) sent back to the borrower on repay.
+	CollateralReturned string `json:"collateralReturned"`
+	StartTime          uint64 `json:"startTime"`
+	Duration           uint64 `json:"duration"`
+	// MaturityTime is startTime + duration (unix seconds).
+	MaturityTime uint64 `json:"maturityTime"`
+	// SecondsToMaturity is 0 once the loan is past maturity.
+	SecondsToMaturity uint64 `json:"secondsToMaturity"`
+	IsOverdue         bool   `json:"isOverdue"`
+}
+
+// RepayQuote describes what it costs to repay one or more loans.
+// Balance and allowance fields are only filled when a sender address is given.
+type RepayQuote struct {
+	Sender                  string            `json:"sender,omitempty"`
+	Loans                   []*LoanRepayQuote `json:"loans"`
+	TotalPrincipal          string            `json:"totalPrincipal"`
+	TotalInterest           string            `json:"totalInterest"`
+	TotalRepayment          string            `json:"totalRepayment"`
+	TotalCollateralReturned string            `json:"totalCollateralReturned"`
+	// UsdtBalance / UsdtAllowance are the sender's current USDT balance and allowance to the pool.
+	UsdtBalance   string `json:"usdtBalance,omitempty"`
+	UsdtAllowance string `json:"usdtAllowance,omitempty"`
+	// BalanceShortfall / AllowanceShortfall are how much USDT is missing to repay everything, "0" if none.
+	BalanceShortfall   string `json:"balanceShortfall,omitempty"`
+	AllowanceShortfall string `json:"allowanceShortfall,omitempty"`
+}
+
+// FaucetGrant records a single testnet faucet payout.
+type FaucetGrant struct {
+	Address string `json:"address"`
+	IP      string `json:"ip,omitempty"`
+	// USDTAmount is the minted MockUSDT amount (6 decimals).
+	USDTAmount string `json:"usdtAmount"`
+	USDTTxHash string `json:"usdtTxHash"`
+	// BNBAmount / BNBTxHash are only set when a BNB drip was sent.
+	BNBAmount string `json:"bnbAmount,omitempty"`
+	BNBTxHash string `json:"bnbTxHash,omitempty"`
+	// GrantedAt is the unix time (seconds) the grant was issued.
+	GrantedAt int64 `json:"grantedAt"`
+}
+
+// FaucetStatus tells a client whether an address can claim from the faucet.
+type FaucetStatus struct {
+	Address    string `json:"address"`
+	CanClaim   bool   `json:"canClaim"`
+	NextClaim  int64  `json:"nextClaimAt,omitempty"`
+	USDTAmount string `json:"usdtAmount"`
+	BNBAmount  string `json:"bnbAmount"`
+	// Grants are the address's recent grants within the quota window, newest last.
+	Grants []*FaucetGrant `json:"grants"`
+}
+
+// PricePoint is a recorded BNB/USD price sample.
+type PricePoint struct {
+	// Price is the BNB/USD price with 18 decimals.
+	Price string `json:"price"`
+	// RoundID is set for samples taken from feed round data.
+	RoundID string `json:"roundId,omitempty"`
+}
+
+// Candle is one OHLC bucket of BNB/USD prices (18 decimals).
+type Candle struct {
+	// Time is the bucket start (unix seconds).
+	Time    int64  `json:"time"`
+	Open    string `json:"open"`
+	High    string `json:"high"`
+	Low     string `json:"low"`
+	Close   string `json:"close"`
+	Samples int    `json:"samples"`
+}
+
+// PriceCandles is an OHLC series for a single price source.
+type PriceCandles struct {
+	Source   string    `json:"source"`
+	Interval string    `json:"interval"`
+	From     int64     `json:"from"`
+	To       int64     `json:"to"`
+	Candles  []*Candle `json:"candles"`
 }
@@ -0,0 +1,233 @@
+package onchain
+
+import (
+	"container/list"
+	"context"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"golang.org/x/sync/singleflight"
+
+	"github.com/cina_dex_backend/internal/model"
+	"github.com/cina_dex_backend/internal/tracing"
+)
+
+// cachedFetchTimeout bounds a shared read; it is detached from the caller
+// that started it so one cancelled request does not fail the others.
+const cachedFetchTimeout = 30 * time.Second
+
+// CachedClient wraps a Client with a read-through cache for per-user and
+// per-loan reads: GetUserPosition, ListUserLoans, GetLoan and GetLoanHealth.
+//
+// An entry is served while it is younger than the TTL and no newer block
+// has been seen through BlockNumber or HeadBlock since it was read, so with
+// block-driven state refresh entries expire on every new block. Concurrent
+// identical reads share one RPC call. The least recently used entries are
+// evicted beyond the size bound. Other methods pass through.
+type CachedClient struct {
+	Client
+
+	ttl  time.Duration
+	size int
+
+	group singleflight.Group
+
+	mu      sync.Mutex
+	entries map[string]*list.Element
+	lru     *list.List // front is most recently used
+
+	// head is the latest block number seen.
+	head atomic.Uint64
+
+	hits, lookups, misses, evictions atomic.Uint64
+}
+
+var _ Client = (*CachedClient)(nil)
+
+type cacheEntry struct {
+	key       string
+	value     interface{}
+	fetchedAt time.Time
+	block     uint64
+}
+
+// NewCachedClient wraps c. size bounds the number of cached entries.
+func NewCachedClient(c Client, ttl time.Duration, size int) *CachedClient {
+	return &CachedClient{
+		Client:  c,
+		ttl:     ttl,
+		size:    size,
+		entries: make(map[string]*list.Element),
+		lru:     list.New(),
+	}
+}
+
+// Stats returns hit / miss counters and the current size.
+func (c *CachedClient) Stats() *model.RPCCacheStats {
+	c.mu.Lock()
+	entries := c.lru.Len()
+	c.mu.Unlock()
+
+	lookups, misses := c.lookups.Load(), c.misses.Load()
+	st := &model.RPCCacheStats{
+		Hits:       c.hits.Load(),
+		Misses:     misses,
+		Evictions:  c.evictions.Load(),
+		Entries:    entries,
+		Capacity:   c.size,
+		TTLSeconds: c.ttl.Seconds(),
+		HeadBlock:  c.head.Load(),
+	}
+	if lookups > misses {
+		st.Coalesced = lookups - misses
+	}
+	return st
+}
+
+func (c *CachedClient) observeBlock(n uint64) {
+	for {
+		cur := c.head.Load()
+		if n <= cur || c.head.CompareAndSwap(cur, n) {
+			return
+		}
+	}
+}
+
+func (c *CachedClient) BlockNumber(ctx context.Context) (uint64, error) {
+	n, err := c.Client.BlockNumber(ctx)
+	if err == nil {
+		c.observeBlock(n)
+	}
+	return n, err
+}
+
+func (c *CachedClient) HeadBlock(ctx context.Context) (*model.BlockRef, error) {
+	ref, err := c.Client.HeadBlock(ctx)
+	if err == nil {
+		c.observeBlock(ref.Number)
+	}
+	return ref, err
+}
+
+func (c *CachedClient) GetUserPosition(ctx context.Context, address string) (*model.UserPosition, error) {
+	return cachedRead(ctx, c, "position:"+strings.ToLower(address), func(ctx context.Context) (*model.UserPosition, error) {
+		return c.Client.GetUserPosition(ctx, address)
+	}, func(p *model.UserPosition) *model.UserPosition {
+		cp := *p
+		cp.LoanIDs = append([]uint64(nil), p.LoanIDs...)
+		return &cp
+	})
+}
+
+func (c *CachedClient) ListUserLoans(ctx context.Context, address string) ([]*model.Loan, error) {
+	return cachedRead(ctx, c, "loans:"+strings.ToLower(address), func(ctx context.Context) ([]*model.Loan, error) {
+		return c.Client.ListUserLoans(ctx, address)
+	}, func(loans []*model.Loan) []*model.Loan {
+		cp := make([]*model.Loan, len(loans))
+		for i, l := range loans {
+			loan := *l
+			cp[i] = &loan
+		}
+		return cp
+	})
+}
+
+func (c *CachedClient) GetLoan(ctx context.Context, id uint64) (*model.Loan, error) {
+	return cachedRead(ctx, c, "loan:"+strconv.FormatUint(id, 10), func(ctx context.Context) (*model.Loan, error) {
+		return c.Client.GetLoan(ctx, id)
+	}, func(l *model.Loan) *model.Loan {
+		cp := *l
+		return &cp
+	})
+}
+
+func (c *CachedClient) GetLoanHealth(ctx context.Context, id uint64) (*model.LoanHealth, error) {
+	return cachedRead(ctx, c, "health:"+strconv.FormatUint(id, 10), func(ctx context.Context) (*model.LoanHealth, error) {
+		return c.Client.GetLoanHealth(ctx, id)
+	}, func(h *model.LoanHealth) *model.LoanHealth {
+		cp := *h
+		return &cp
+	})
+}
+
+// cachedRead serves key from the cache or reads it through fetch, sharing the
+// read with concurrent callers. Callers get their own copy of the value so
+// they may modify it. Errors are not cached.
+func cachedRead[T any](ctx context.Context, c *CachedClient, key string, fetch func(context.Context) (T, error), clone func(T) T) (_ T, err error) {
+	ctx, span := tracing.Start(ctx, "CachedClient.read", tracing.CacheKey.String(key))
+	defer func() { tracing.End(span, err) }()
+
+	if v, ok := c.get(key); ok {
+		c.hits.Add(1)
+		span.SetAttributes(tracing.CacheResult.String("hit"))
+		return clone(v.(T)), nil
+	}
+	c.lookups.Add(1)
+	span.SetAttributes(tracing.CacheResult.String("miss"))
+
+	ch := c.group.DoChan(key, func() (interface{}, error) {
+		c.misses.Add(1)
+		block := c.head.Load()
+		fetchedAt := time.Now()
+
+		fctx, cancel := context.WithTimeout(context.WithoutCancel(ctx), cachedFetchTimeout)
+		defer cancel()
+		v, err := fetch(fctx)
+		if err != nil {
+			return nil, err
+		}
+		c.put(key, v, fetchedAt, block)
+		return v, nil
+	})
+
+	var zero T
+	select {
+	case <-ctx.Done():
+		return zero, ctx.Err()
+	case res := <-ch:
+		if res.Err != nil {
+			return zero, res.Err
+		}
+		return clone(res.Val.(T)), nil
+	}
+}
+
+func (c *CachedClient) get(key string) (interface{}, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	el, ok := c.entries[key]
+	if !ok {
+		return nil, false
+	}
+	e := el.Value.(*cacheEntry)
+	if time.Since(e.fetchedAt) >= c.ttl || e.block < c.head.Load() {
+		c.lru.Remove(el)
+		delete(c.entries, key)
+		return nil, false
+	}
+	c.lru.MoveToFront(el)
+	return e.value, true
+}
+
+func (c *CachedClient) put(key string, value interface{}, fetchedAt time.Time, block uint64) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	e := &cacheEntry{key: key, value: value, fetchedAt: fetchedAt, block: block}
+	if el, ok := c.entries[key]; ok {
+		el.Value = e
+		c.lru.MoveToFront(el)
+		return
+	}
+	c.entries[key] = c.lru.PushFront(e)
+	for c.lru.Len() > c.size {
+		oldest := c.lru.Back()
+		c.lru.Remove(oldest)
+		delete(c.entries, oldest.Value.(*cacheEntry).key)
+		c.evictions.Add(1)
+	}
+}
@@ -12,12 +12,34 @@ import (
 // the ABI files in go_back/abi.
 type Client interface {
 	GetPoolState(ctx context.Context) (*model.PoolState, error)
+	// GetPoolStateAt reads getPoolState() as of the given block number.
+	GetPoolStateAt(ctx context.Context, block uint64) (*model.PoolState, error)
+	// HeadBlock returns the latest block number and timestamp.
+	HeadBlock(ctx context.Context) (*model.BlockRef, error)
+	// BlockNumber returns the latest block number (eth_blockNumber), a cheap
+	// way to poll for new blocks.
+	BlockNumber(ctx context.Context) (uint64, error)
+	// ChainID returns the chain id reported by the RPC endpoint.
+	ChainID(ctx context.Context) (uint64, error)
 	GetUserPosition(ctx context.Context, address string) (*model.UserPosition, error)
 	ListUserLoans(ctx context.Context, address string) ([]*model.Loan, error)
 	GetLoan(ctx context.Context, id uint64) (*model.Loan, error)
+	// NextLoanID returns LendingPool.nextLoanId(); loan IDs are 0 .. NextLoanID-1.
+	NextLoanID(ctx context.Context) (uint64, error)
 	GetLoanHealth(ctx context.Context, id uint64) (*model.LoanHealth, error)
 	// GetLenderPosition reads the LP position for a lender (fToken balance, exchangeRate, underlyingBalance).
 	GetLenderPosition(ctx context.Context, address string) (*model.LenderPosition, error)
 	// GetNativePrice returns the BNB/USD price with 18 decimals from ChainlinkOracle.getPrice(address(0)).
 	GetNativePrice(ctx context.Context) (*big.Int, error)
+	// GetLatestRound reads latestRoundData() from the raw BNB/USD price feed (ChainConfig.PriceFeed).
+	GetLatestRound(ctx context.Context) (*model.PriceRound, error)
+	// GetV3TWAPTick returns the mean tick of a Uniswap V3-style pool over the last window seconds.
+	GetV3TWAPTick(ctx context.Context, pool string, window uint32) (int64, error)
+	// GetUSDTBalance returns the borrow asset (USDT/MockUSDT) balance of owner, 6 decimals.
+	GetUSDTBalance(ctx context.Context, owner string) (*big.Int, error)
+	// GetUSDTAllowance returns the borrow asset allowance granted by owner to the LendingPool.
+	GetUSDTAllowance(ctx context.Context, owner string) (*big.Int, error)
+	// GetActivityEvents decodes LendingPool events and MockUSDT mints in the
+	// inclusive block range [from, to].
+	GetActivityEvents(ctx context.Context, from, to uint64) ([]*model.ActivityEvent, error)
 }
@@ -2,15 +2,22 @@ package onchain
 
 import (
 	"context"
+	"encoding/hex"
 	"fmt"
+	"log/slog"
 	"math/big"
 	"strings"
+	"sync"
+	"time"
 
 	"github.com/cina_dex_backend/internal/config"
+	"github.com/cina_dex_backend/internal/metrics"
 	"github.com/cina_dex_backend/internal/model"
+	"github.com/cina_dex_backend/internal/tracing"
 	"github.com/ethereum/go-ethereum"
 	"github.com/ethereum/go-ethereum/common"
 	"github.com/ethereum/go-ethereum/ethclient"
+	"go.opentelemetry.io/otel/attribute"
 )
 
 // EthClient is a lightweight on-chain client that talks directly to the
@@ -23,6 +30,15 @@ type EthClient struct {
 	rpc         *ethclient.Client
 	lendingPool common.Address
 	oracle      common.Address
+	// token is the borrow asset (USDT on mainnet, MockUSDT on testnet).
+	token common.Address
+	// mockUSDT is set on testnet only; its mints are indexed as faucet activity.
+	mockUSDT common.Address
+	// priceFeed is the raw Chainlink-compatible BNB/USD aggregator.
+	priceFeed common.Address
+
+	feedDecimalsMu sync.Mutex
+	feedDecimals   uint8
 }
 
 // NewEthClient dials the configured RPC endpoint and prepares a client that
@@ -48,6 +64,21 @@ func NewEthClient(ctx context.Context, cfg *config.Config) (*EthClient, error) {
 		client.oracle = common.HexToAddress(cfg.ChainConfig.ChainlinkOracle)
 	}
 
+	if isHexAddress(cfg.ChainConfig.PriceFeed) {
+		client.priceFeed = common.HexToAddress(cfg.ChainConfig.PriceFeed)
+	}
+
+	token := cfg.ChainConfig.USDT
+	if token == "" {
+		token = cfg.ChainConfig.MockUSDT
+	}
+	if isHexAddress(token) {
+		client.token = common.HexToAddress(token)
+	}
+	if isHexAddress(cfg.ChainConfig.MockUSDT) {
+		client.mockUSDT = common.HexToAddress(cfg.ChainConfig.MockUSDT)
+	}
+
 	return client, nil
 }
 
@@ -61,16 +92,87 @@ var (
 	selectorGetUserLoans    = []byte{0x02, 0xbf, 0x32, 0x1f} // getUserLoans(address)
 	selectorGetLoanHealth   = []byte{0xb6, 0xe0, 0x76, 0x88} // getLoanHealth(uint256)
 	selectorLoans           = []byte{0xe1, 0xec, 0x3c, 0x68} // loans(uint256)
+	selectorNextLoanID      = []byte{0x87, 0xc5, 0x14, 0x59} // nextLoanId()
 	selectorGetLenderPos    = []byte{0x5d, 0x41, 0x3f, 0xa2} // getLenderPosition(address)
 	selectorGetPrice        = []byte{0x41, 0x97, 0x6e, 0x09} // getPrice(address)
+	selectorBalanceOf       = []byte{0x70, 0xa0, 0x82, 0x31} // balanceOf(address)
+	selectorAllowance       = []byte{0xdd, 0x62, 0xed, 0x3e} // allowance(address,address)
+	selectorLatestRoundData = []byte{0xfe, 0xaf, 0x96, 0x8c} // latestRoundData()
+	selectorDecimals        = []byte{0x31, 0x3c, 0xe5, 0x67} // decimals()
+	selectorObserve         = []byte{0x88, 0x3b, 0xdb, 0xfd} // observe(uint32[])
 )
 
+// selectorNames labels RPC metrics by the function called.
+var selectorNames = map[string]string{
+	string(selectorGetPoolState):    "getPoolState",
+	string(selectorGetUserPosition): "getUserPosition",
+	string(selectorGetUserLoans):    "getUserLoans",
+	string(selectorGetLoanHealth):   "getLoanHealth",
+	string(selectorLoans):           "loans",
+	string(selectorNextLoanID):      "nextLoanId",
+	string(selectorGetLenderPos):    "getLenderPosition",
+	string(selectorGetPrice):        "getPrice",
+	string(selectorBalanceOf):       "balanceOf",
+	string(selectorAllowance):       "allowance",
+	string(selectorLatestRoundData): "latestRoundData",
+	string(selectorDecimals):        "decimals",
+	string(selectorObserve):         "observe",
+}
+
 // GetPoolState calls LendingPool.getPoolState() and maps the result to model.PoolState.
 func (c *EthClient) GetPoolState(ctx context.Context) (*model.PoolState, error) {
+	return c.getPoolState(ctx, nil)
+}
+
+// GetPoolStateAt calls LendingPool.getPoolState() against the state of a given block.
+func (c *EthClient) GetPoolStateAt(ctx context.Context, block uint64) (*model.PoolState, error) {
+	return c.getPoolState(ctx, new(big.Int).SetUint64(block))
+}
+
+// HeadBlock returns the number and timestamp of the latest block.
+func (c *EthClient) HeadBlock(ctx context.Context) (*model.BlockRef, error) {
+	ctx, done := startRPC(ctx, "eth_getBlockByNumber", "")
+	header, err := c.rpc.HeaderByNumber(ctx, nil)
+	done(err)
+	if err != nil {
+		return nil, fmt.Errorf("get head block: %w", err)
+	}
+	return &model.BlockRef{
+		Number: header.Number.Uint64(),
+		Time:   header.Time,
+	}, nil
+}
+
+func (c *EthClient) BlockNumber(ctx context.Context) (uint64, error) {
+	ctx, done := startRPC(ctx, "eth_blockNumber", "")
+	n, err := c.rpc.BlockNumber(ctx)
+	done(err)
+	if err != nil {
+		return 0, fmt.Errorf("get block number: %w", err)
+	}
+	return n, nil
+}
+
+// Close closes the underlying RPC connection.
+func (c *EthClient) Close() {
+	c.rpc.Close()
+}
+
+func (c *EthClient) ChainID(ctx context.Context) (uint64, error) {
+	ctx, done := startRPC(ctx, "eth_chainId", "")
+	id, err := c.rpc.ChainID(ctx)
+	done(err)
+	if err != nil {
+		return 0, fmt.Errorf("get chain id: %w", err)
+	}
+	return id.Uint64(), nil
+}
+
+func (c *EthClient) getPoolState(ctx context.Context, block *big.Int) (*model.PoolState, error) {
 	data := make([]byte, len(selectorGetPoolState))
 	copy(data, selectorGetPoolState)
 
-	out, err := c.call(ctx, data)
+	out, err := c.callAt(ctx, c.lendingPool, data, block)
 	if err != nil {
 		return nil, fmt.Errorf("call getPoolState: %w", err)
 	}
@@ -235,6 +337,22 @@ func (c *EthClient) GetLoan(ctx context.Context, id uint64) (*model.Loan, error)
 	}, nil
 }
 
+// NextLoanID calls nextLoanId().
+func (c *EthClient) NextLoanID(ctx context.Context) (uint64, error) {
+	data := make([]byte, len(selectorNextLoanID))
+	copy(data, selectorNextLoanID)
+
+	out, err := c.call(ctx, data)
+	if err != nil {
+		return 0, fmt.Errorf("call nextLoanId: %w", err)
+	}
+	words, err := splitWords(out, 1)
+	if err != nil {
+		return 0, fmt.Errorf("decode nextLoanId: %w", err)
+	}
+	return words[0].Uint64(), nil
+}
+
 // GetLoanHealth calls getLoanHealth(uint256).
 func (c *EthClient) GetLoanHealth(ctx context.Context, id uint64) (*model.LoanHealth, error) {
 	data := make([]byte, len(selectorGetLoanHealth)+32)
@@ -273,12 +391,7 @@ func (c *EthClient) GetNativePrice(ctx context.Context) (*big.Int, error) {
 	var zero common.Address
 	copy(data[len(selectorGetPrice):], packAddress(zero))
 
-	msg := ethereum.CallMsg{
-		To:   &c.oracle,
-		Data: data,
-	}
-
-	out, err := c.rpc.CallContract(ctx, msg, nil)
+	out, err := c.callContract(ctx, c.oracle, data)
 	if err != nil {
 		return nil, fmt.Errorf("call getPrice(address(0)): %w", err)
 	}
@@ -290,13 +403,257 @@ func (c *EthClient) GetNativePrice(ctx context.Context) (*big.Int, error) {
 	return price, nil
 }
 
+// GetLatestRound reads latestRoundData() and decimals() from the raw price feed.
+// The answer is also scaled to 18 decimals for comparison with the oracle wrapper.
+func (c *EthClient) GetLatestRound(ctx context.Context) (*model.PriceRound, error) {
+	if (c.priceFeed == common.Address{}) {
+		return nil, fmt.Errorf("price feed address not configured")
+	}
+
+	decimals, err := c.getFeedDecimals(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	out, err := c.callContract(ctx, c.priceFeed, selectorLatestRoundData)
+	if err != nil {
+		return nil, fmt.Errorf("call latestRoundData: %w", err)
+	}
+
+	// (uint80 roundId, int256 answer, uint256 startedAt, uint256 updatedAt, uint80 answeredInRound)
+	words, err := splitWords(out, 5)
+	if err != nil {
+		return nil, fmt.Errorf("decode latestRoundData: %w", err)
+	}
+
+	answer := decodeInt256(out[32:64])
+
+	price := new(big.Int).Set(answer)
+	switch {
+	case decimals < 18:
+		price.Mul(price, new(big.Int).Exp(big.NewInt(10), big.NewInt(int64(18-decimals)), nil))
+	case decimals > 18:
+		price.Quo(price, new(big.Int).Exp(big.NewInt(10), big.NewInt(int64(decimals-18)), nil))
+	}
+
+	return &model.PriceRound{
+		RoundID:         words[0].String(),
+		Answer:          answer.String(),
+		Decimals:        decimals,
+		Price:           price.String(),
+		StartedAt:       words[2].Uint64(),
+		UpdatedAt:       words[3].Uint64(),
+		AnsweredInRound: words[4].String(),
+	}, nil
+}
+
+// GetV3TWAPTick calls observe([window, 0]) on a Uniswap/PancakeSwap V3 pool
+// and returns the arithmetic mean tick over the last window seconds,
+// rounded towards negative infinity like OracleLibrary.consult.
+func (c *EthClient) GetV3TWAPTick(ctx context.Context, pool string, window uint32) (int64, error) {
+	if !isHexAddress(pool) {
+		return 0, fmt.Errorf("invalid pool address: %s", pool)
+	}
+	if window == 0 {
+		return 0, fmt.Errorf("twap window must be positive")
+	}
+
+	// observe(uint32[] secondsAgos) with secondsAgos = [window, 0]
+	data := make([]byte, len(selectorObserve)+4*32)
+	copy(data, selectorObserve)
+	off := len(selectorObserve)
+	copy(data[off:], packUint64(32))
+	copy(data[off+32:], packUint64(2))
+	copy(data[off+64:], packUint64(uint64(window)))
+	copy(data[off+96:], packUint64(0))
+
+	out, err := c.callContract(ctx, common.HexToAddress(pool), data)
+	if err != nil {
+		return 0, fmt.Errorf("call observe: %w", err)
+	}
+
+	// (int56[] tickCumulatives, uint160[] secondsPerLiquidityCumulativeX128s)
+	if len(out) < 64 {
+		return 0, fmt.Errorf("observe output too short")
+	}
+	offset := new(big.Int).SetBytes(out[0:32]).Int64()
+	if offset < 0 || int(offset)+3*32 > len(out) {
+		return 0, fmt.Errorf("invalid tickCumulatives offset %d", offset)
+	}
+	if n := new(big.Int).SetBytes(out[offset : offset+32]).Int64(); n != 2 {
+		return 0, fmt.Errorf("unexpected tickCumulatives length %d", n)
+	}
+	older := decodeInt256(out[offset+32 : offset+64])
+	newer := decodeInt256(out[offset+64 : offset+96])
+
+	delta := new(big.Int).Sub(newer, older)
+	w := big.NewInt(int64(window))
+	tick, rem := new(big.Int).QuoRem(delta, w, new(big.Int))
+	if delta.Sign() < 0 && rem.Sign() != 0 {
+		tick.Sub(tick, big.NewInt(1))
+	}
+	return tick.Int64(), nil
+}
+
+// getFeedDecimals reads decimals() from the price feed once and caches it.
+func (c *EthClient) getFeedDecimals(ctx context.Context) (uint8, error) {
+	c.feedDecimalsMu.Lock()
+	defer c.feedDecimalsMu.Unlock()
+	if c.feedDecimals != 0 {
+		return c.feedDecimals, nil
+	}
+
+	out, err := c.callContract(ctx, c.priceFeed, selectorDecimals)
+	if err != nil {
+		return 0, fmt.Errorf("call decimals: %w", err)
+	}
+	words, err := splitWords(out, 1)
+	if err != nil {
+		return 0, fmt.Errorf("decode decimals: %w", err)
+	}
+	d := words[0].Uint64()
+	if d == 0 || d > 36 {
+		return 0, fmt.Errorf("unexpected feed decimals %d", d)
+	}
+	c.feedDecimals = uint8(d)
+	return c.feedDecimals, nil
+}
+
+// GetUSDTBalance calls balanceOf(owner) on the borrow asset (USDT/MockUSDT).
+func (c *EthClient) GetUSDTBalance(ctx context.Context, owner string) (*big.Int, error) {
+	if (c.token == common.Address{}) {
+		return nil, fmt.Errorf("usdt address not configured")
+	}
+
+	data := make([]byte, len(selectorBalanceOf)+32)
+	copy(data, selectorBalanceOf)
+	copy(data[len(selectorBalanceOf):], packAddress(common.HexToAddress(owner)))
+
+	out, err := c.callContract(ctx, c.token, data)
+	if err != nil {
+		return nil, fmt.Errorf("call balanceOf: %w", err)
+	}
+	words, err := splitWords(out, 1)
+	if err != nil {
+		return nil, fmt.Errorf("decode balanceOf: %w", err)
+	}
+	return words[0], nil
+}
+
+// GetUSDTAllowance calls allowance(owner, lendingPool) on the borrow asset.
+func (c *EthClient) GetUSDTAllowance(ctx context.Context, owner string) (*big.Int, error) {
+	if (c.token == common.Address{}) {
+		return nil, fmt.Errorf("usdt address not configured")
+	}
+
+	data := make([]byte, len(selectorAllowance)+64)
+	copy(data, selectorAllowance)
+	copy(data[len(selectorAllowance):], packAddress(common.HexToAddress(owner)))
+	copy(data[len(selectorAllowance)+32:], packAddress(c.lendingPool))
+
+	out, err := c.callContract(ctx, c.token, data)
+	if err != nil {
+		return nil, fmt.Errorf("call allowance: %w", err)
+	}
+	words, err := splitWords(out, 1)
+	if err != nil {
+		return nil, fmt.Errorf("decode allowance: %w", err)
+	}
+	return words[0], nil
+}
+
 // call executes a read-only call against the LendingPool contract.
 func (c *EthClient) call(ctx context.Context, data []byte) ([]byte, error) {
+	return c.callAt(ctx, c.lendingPool, data, nil)
+}
+
+// callContract executes a read-only call against an arbitrary contract.
+func (c *EthClient) callContract(ctx context.Context, to common.Address, data []byte) ([]byte, error) {
+	return c.callAt(ctx, to, data, nil)
+}
+
+// callAt executes a read-only call at the given block (nil for latest) and
+// records it by contract function.
+func (c *EthClient) callAt(ctx context.Context, to common.Address, data []byte, block *big.Int) ([]byte, error) {
 	msg := ethereum.CallMsg{
-		To:   &c.lendingPool,
+		To:   &to,
 		Data: data,
 	}
-	return c.rpc.CallContract(ctx, msg, nil)
+	attrs := callAttributes(data)
+	if block != nil {
+		attrs = append(attrs, tracing.BlockKey.Int64(block.Int64()))
+	}
+	ctx, done := startRPC(ctx, "eth_call", selectorName(data), attrs...)
+	out, err := c.rpc.CallContract(ctx, msg, block)
+	done(err)
+	return out, err
+}
+
+// startRPC starts a span for an RPC call; the returned func records the
+// call's metrics and ends the span.
+func startRPC(ctx context.Context, method, function string, attrs ...attribute.KeyValue) (context.Context, func(error)) {
+	start := time.Now()
+	name := method
+	attrs = append(attrs, tracing.RPCMethod.String(method))
+	if function != "" {
+		name += " " + function
+		attrs = append(attrs, tracing.FunctionKey.String(function))
+	}
+	ctx, span := tracing.Start(ctx, name, attrs...)
+	return ctx, func(err error) {
+		metrics.ObserveRPC(method, function, start, err)
+		tracing.End(span, err)
+		logRPC(ctx, name, start, err)
+	}
+}
+
+// logRPC logs an RPC call at debug level, or at warn level when it failed for
+// a reason other than the caller giving up. The context carries the request
+// ID of the HTTP request that triggered the call, if any.
+func logRPC(ctx context.Context, name string, start time.Time, err error) {
+	level := slog.LevelDebug
+	if err != nil && ctx.Err() == nil {
+		level = slog.LevelWarn
+	}
+	attrs := []slog.Attr{
+		slog.String("call", name),
+		slog.Int64("duration_ms", time.Since(start).Milliseconds()),
+	}
+	if err != nil {
+		attrs = append(attrs, slog.String("err", err.Error()))
+	}
+	slog.LogAttrs(ctx, level, "rpc call", attrs...)
+}
+
+// callAttributes describes call data for spans: the selector and, for the
+// per-user / per-loan view functions, the address or loan id argument.
+func callAttributes(data []byte) []attribute.KeyValue {
+	if len(data) < 4 {
+		return nil
+	}
+	attrs := []attribute.KeyValue{tracing.SelectorKey.String("0x" + hex.EncodeToString(data[:4]))}
+	if len(data) < 36 {
+		return attrs
+	}
+	arg := data[4:36]
+	switch selectorNames[string(data[:4])] {
+	case "getUserPosition", "getUserLoans", "getLenderPosition", "balanceOf", "allowance":
+		attrs = append(attrs, tracing.Address(common.BytesToAddress(arg[12:]).Hex()))
+	case "getLoanHealth", "loans":
+		attrs = append(attrs, tracing.LoanID(new(big.Int).SetBytes(arg).Uint64()))
+	}
+	return attrs
+}
+
+// selectorName names the function called by ABI-encoded call data.
+func selectorName(data []byte) string {
+	if len(data) < 4 {
+		return "unknown"
+	}
+	if name, ok := selectorNames[string(data[:4])]; ok {
+		return name
+	}
+	return "0x" + hex.EncodeToString(data[:4])
 }
 
 // splitWords splits ABI-encoded static return data into N uint256 words.
@@ -313,6 +670,15 @@ func splitWords(data []byte, n int) ([]*big.Int, error) {
 	return words, nil
 }
 
+// decodeInt256 decodes a two's complement signed 32-byte ABI word.
+func decodeInt256(word []byte) *big.Int {
+	v := new(big.Int).SetBytes(word)
+	if len(word) > 0 && word[0]&0x80 != 0 {
+		v.Sub(v, new(big.Int).Lsh(big.NewInt(1), 256))
+	}
+	return v
+}
+
 // decodeUint256Array decodes a dynamic uint256[] whose length word starts at the given offset.
 func decodeUint256Array(data []byte, offset int) ([]uint64, error) {
 	if offset < 0 || offset+32 > len(data) {
@@ -0,0 +1,105 @@
+package onchain
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/ethereum/go-ethereum/accounts/abi"
+	"github.com/ethereum/go-ethereum/common"
+)
+
+// eventLayout is the event shape decodeActivityLog expects: its topic and
+// which inputs are indexed.
+type eventLayout struct {
+	name    string
+	topic   common.Hash
+	indexed []bool
+}
+
+var (
+	lendingPoolEvents = []eventLayout{
+		{"Deposit", topicDeposit, []bool{true, false, false}},
+		{"Withdraw", topicWithdraw, []bool{true, false, false}},
+		{"Borrow", topicBorrow, []bool{true, true, false, false, false, false}},
+		{"Repay", topicRepay, []bool{true, true, false}},
+		{"Liquidate", topicLiquidate, []bool{true, true, true, false, false}},
+	}
+	mockUSDTEvents = []eventLayout{
+		{"Transfer", topicTransfer, []bool{true, true, false}},
+	}
+)
+
+// ErrABINotFound is returned by VerifyEventABI when an ABI file is missing.
+var ErrABINotFound = errors.New("abi file not found")
+
+// VerifyEventABI checks the event topics and indexed inputs the decoder
+// relies on against the contract ABIs in dir (LendingPool.json and, with
+// mockUSDT set, MockUSDT.json), so a contract change fails loudly instead of
+// leaving history empty. It returns ErrABINotFound when an ABI file is absent.
+func VerifyEventABI(dir string, mockUSDT bool) error {
+	if err := verifyEvents(filepath.Join(dir, "LendingPool.json"), lendingPoolEvents); err != nil {
+		return err
+	}
+	if mockUSDT {
+		return verifyEvents(filepath.Join(dir, "MockUSDT.json"), mockUSDTEvents)
+	}
+	return nil
+}
+
+func verifyEvents(path string, want []eventLayout) error {
+	parsed, err := loadABI(path)
+	if err != nil {
+		return err
+	}
+	var problems []string
+	for _, w := range want {
+		ev, ok := parsed.Events[w.name]
+		if !ok {
+			problems = append(problems, fmt.Sprintf("event %s missing", w.name))
+			continue
+		}
+		if ev.ID != w.topic {
+			problems = append(problems, fmt.Sprintf("event %s is %s, decoder expects a different signature", w.name, ev.Sig))
+			continue
+		}
+		for i, in := range ev.Inputs {
+			if in.Indexed != w.indexed[i] {
+				problems = append(problems, fmt.Sprintf("event %s input %q indexed=%t, decoder expects %t", w.name, in.Name, in.Indexed, w.indexed[i]))
+			}
+		}
+	}
+	if len(problems) > 0 {
+		return fmt.Errorf("%s does not match the event decoder: %s", path, strings.Join(problems, "; "))
+	}
+	return nil
+}
+
+// loadABI reads a plain ABI array or a build artifact with an "abi" field.
+func loadABI(path string) (*abi.ABI, error) {
+	bz, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, fmt.Errorf("%w: %s", ErrABINotFound, path)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("read abi: %w", err)
+	}
+	if trimmed := bytes.TrimSpace(bz); len(trimmed) > 0 && trimmed[0] == '{' {
+		var artifact struct {
+			ABI json.RawMessage `json:"abi"`
+		}
+		if err := json.Unmarshal(trimmed, &artifact); err != nil {
+			return nil, fmt.Errorf("decode abi artifact %s: %w", path, err)
+		}
+		bz = artifact.ABI
+	}
+	parsed, err := abi.JSON(bytes.NewReader(bz))
+	if err != nil {
+		return nil, fmt.Errorf("parse abi %s: %w", path, err)
+	}
+	return &parsed, nil
+}
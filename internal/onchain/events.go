@@ -0,0 +1,205 @@
+package onchain
+
+import (
+	"context"
+	"fmt"
+	"math/big"
+	"sort"
+
+	"github.com/cina_dex_backend/internal/model"
+	"github.com/cina_dex_backend/internal/tracing"
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto"
+	"go.opentelemetry.io/otel/attribute"
+)
+
+// Event topics. The LendingPool signatures follow go_back/abi/LendingPool.json,
+// which VerifyEventABI checks at startup; update them together with the
+// decoders below and lendingPoolEvents if the contract changes.
+var (
+	topicDeposit   = crypto.Keccak256Hash([]byte("Deposit(address,uint256,uint256)"))
+	topicWithdraw  = crypto.Keccak256Hash([]byte("Withdraw(address,uint256,uint256)"))
+	topicBorrow    = crypto.Keccak256Hash([]byte("Borrow(uint256,address,uint256,uint256,uint256,uint256)"))
+	topicRepay     = crypto.Keccak256Hash([]byte("Repay(uint256,address,uint256)"))
+	topicLiquidate = crypto.Keccak256Hash([]byte("Liquidate(uint256,address,address,uint256,uint256)"))
+	topicTransfer  = crypto.Keccak256Hash([]byte("Transfer(address,address,uint256)"))
+)
+
+// GetActivityEvents reads LendingPool events and, on testnet, MockUSDT mints
+// (Transfer from the zero address) in [from, to], ordered by block and log index.
+func (c *EthClient) GetActivityEvents(ctx context.Context, from, to uint64) ([]*model.ActivityEvent, error) {
+	ctx, span := tracing.Start(ctx, "onchain.GetActivityEvents",
+		attribute.Int64("eth.from_block", int64(from)), attribute.Int64("eth.to_block", int64(to)))
+	defer span.End()
+
+	logsCtx, done := startRPC(ctx, "eth_getLogs", "")
+	logs, err := c.rpc.FilterLogs(logsCtx, ethereum.FilterQuery{
+		FromBlock: new(big.Int).SetUint64(from),
+		ToBlock:   new(big.Int).SetUint64(to),
+		Addresses: []common.Address{c.lendingPool},
+		Topics:    [][]common.Hash{{topicDeposit, topicWithdraw, topicBorrow, topicRepay, topicLiquidate}},
+	})
+	done(err)
+	if err != nil {
+		return nil, fmt.Errorf("get lending pool logs: %w", err)
+	}
+
+	if (c.mockUSDT != common.Address{}) {
+		mintsCtx, done := startRPC(ctx, "eth_getLogs", "")
+		mints, err := c.rpc.FilterLogs(mintsCtx, ethereum.FilterQuery{
+			FromBlock: new(big.Int).SetUint64(from),
+			ToBlock:   new(big.Int).SetUint64(to),
+			Addresses: []common.Address{c.mockUSDT},
+			Topics:    [][]common.Hash{{topicTransfer}, {common.Hash{}}},
+		})
+		done(err)
+		if err != nil {
+			return nil, fmt.Errorf("get mock usdt logs: %w", err)
+		}
+		logs = append(logs, mints...)
+	}
+
+	sort.Slice(logs, func(i, j int) bool {
+		if logs[i].BlockNumber != logs[j].BlockNumber {
+			return logs[i].BlockNumber < logs[j].BlockNumber
+		}
+		return logs[i].Index < logs[j].Index
+	})
+
+	blockTimes := make(map[uint64]uint64)
+	events := make([]*model.ActivityEvent, 0, len(logs))
+	for i := range logs {
+		lg := &logs[i]
+		if lg.Removed {
+			continue
+		}
+		ev, err := decodeActivityLog(lg)
+		if err != nil {
+			return nil, fmt.Errorf("decode log %s#%d: %w", lg.TxHash.Hex(), lg.Index, err)
+		}
+
+		t, ok := blockTimes[lg.BlockNumber]
+		if !ok {
+			headerCtx, done := startRPC(ctx, "eth_getBlockByNumber", "", tracing.BlockKey.Int64(int64(lg.BlockNumber)))
+			header, err := c.rpc.HeaderByNumber(headerCtx, new(big.Int).SetUint64(lg.BlockNumber))
+			done(err)
+			if err != nil {
+				return nil, fmt.Errorf("get block %d: %w", lg.BlockNumber, err)
+			}
+			t = header.Time
+			blockTimes[lg.BlockNumber] = t
+		}
+		ev.BlockTime = t
+		events = append(events, ev)
+	}
+	return events, nil
+}
+
+// decodeActivityLog maps a single log to an ActivityEvent.
+func decodeActivityLog(lg *types.Log) (*model.ActivityEvent, error) {
+	if len(lg.Topics) == 0 {
+		return nil, fmt.Errorf("log without topics")
+	}
+	ev := &model.ActivityEvent{
+		BlockNumber: lg.BlockNumber,
+		TxHash:      lg.TxHash.Hex(),
+		LogIndex:    lg.Index,
+	}
+
+	switch lg.Topics[0] {
+	case topicDeposit:
+		// Deposit(address indexed user, uint256 amount, uint256 fTokenMinted)
+		words, err := eventWords(lg, 2, 2)
+		if err != nil {
+			return nil, err
+		}
+		ev.Type = model.ActivityDeposit
+		ev.Account = topicAddress(lg.Topics[1])
+		ev.Amount = words[0].String()
+		ev.FTokenAmount = words[1].String()
+
+	case topicWithdraw:
+		// Withdraw(address indexed user, uint256 fTokenBurned, uint256 amount)
+		words, err := eventWords(lg, 2, 2)
+		if err != nil {
+			return nil, err
+		}
+		ev.Type = model.ActivityWithdraw
+		ev.Account = topicAddress(lg.Topics[1])
+		ev.FTokenAmount = words[0].String()
+		ev.Amount = words[1].String()
+
+	case topicBorrow:
+		// Borrow(uint256 indexed loanId, address indexed borrower, uint256 principal,
+		//        uint256 collateralAmount, uint256 repaymentAmount, uint256 duration)
+		words, err := eventWords(lg, 3, 4)
+		if err != nil {
+			return nil, err
+		}
+		ev.Type = model.ActivityBorrow
+		ev.LoanID = topicLoanID(lg.Topics[1])
+		ev.Account = topicAddress(lg.Topics[2])
+		ev.Amount = words[0].String()
+		ev.CollateralAmount = words[1].String()
+		ev.RepaymentAmount = words[2].String()
+		ev.Duration = words[3].Uint64()
+
+	case topicRepay:
+		// Repay(uint256 indexed loanId, address indexed borrower, uint256 repaymentAmount)
+		words, err := eventWords(lg, 3, 1)
+		if err != nil {
+			return nil, err
+		}
+		ev.Type = model.ActivityRepay
+		ev.LoanID = topicLoanID(lg.Topics[1])
+		ev.Account = topicAddress(lg.Topics[2])
+		ev.Amount = words[0].String()
+
+	case topicLiquidate:
+		// Liquidate(uint256 indexed loanId, address indexed liquidator, address indexed borrower,
+		//           uint256 repaymentAmount, uint256 collateralSeized)
+		words, err := eventWords(lg, 4, 2)
+		if err != nil {
+			return nil, err
+		}
+		ev.Type = model.ActivityLiquidate
+		ev.LoanID = topicLoanID(lg.Topics[1])
+		ev.Liquidator = topicAddress(lg.Topics[2])
+		ev.Account = topicAddress(lg.Topics[3])
+		ev.Amount = words[0].String()
+		ev.CollateralAmount = words[1].String()
+
+	case topicTransfer:
+		// Transfer(address indexed from, address indexed to, uint256 value), from = 0x0
+		words, err := eventWords(lg, 3, 1)
+		if err != nil {
+			return nil, err
+		}
+		ev.Type = model.ActivityFaucetMint
+		ev.Account = topicAddress(lg.Topics[2])
+		ev.Amount = words[0].String()
+
+	default:
+		return nil, fmt.Errorf("unknown topic %s", lg.Topics[0].Hex())
+	}
+	return ev, nil
+}
+
+// eventWords checks the topic count and splits the non-indexed data words.
+func eventWords(lg *types.Log, topics, words int) ([]*big.Int, error) {
+	if len(lg.Topics) != topics {
+		return nil, fmt.Errorf("expected %d topics, got %d", topics, len(lg.Topics))
+	}
+	return splitWords(lg.Data, words)
+}
+
+func topicAddress(h common.Hash) string {
+	return common.BytesToAddress(h.Bytes()[12:]).Hex()
+}
+
+func topicLoanID(h common.Hash) *uint64 {
+	id := new(big.Int).SetBytes(h.Bytes()).Uint64()
+	return &id
+}
@@ -0,0 +1,70 @@
+package onchain
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/cina_dex_backend/internal/model"
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/ethclient"
+)
+
+// HeadSubscription streams new block headers (eth_subscribe newHeads) from a
+// WebSocket RPC endpoint.
+type HeadSubscription struct {
+	rpc   *ethclient.Client
+	sub   ethereum.Subscription
+	heads chan *model.BlockRef
+	done  chan struct{}
+}
+
+// SubscribeHeads dials wsURL and subscribes to new heads.
+func SubscribeHeads(ctx context.Context, wsURL string) (*HeadSubscription, error) {
+	rpc, err := ethclient.DialContext(ctx, wsURL)
+	if err != nil {
+		return nil, fmt.Errorf("dial ws rpc: %w", err)
+	}
+	raw := make(chan *types.Header, 16)
+	sub, err := rpc.SubscribeNewHead(ctx, raw)
+	if err != nil {
+		rpc.Close()
+		return nil, fmt.Errorf("subscribe new heads: %w", err)
+	}
+
+	s := &HeadSubscription{
+		rpc:   rpc,
+		sub:   sub,
+		heads: make(chan *model.BlockRef, 16),
+		done:  make(chan struct{}),
+	}
+	go func() {
+		defer close(s.heads)
+		for {
+			select {
+			case <-s.done:
+				return
+			case <-sub.Err():
+				return
+			case h := <-raw:
+				select {
+				case s.heads <- &model.BlockRef{Number: h.Number.Uint64(), Time: h.Time}:
+				case <-s.done:
+					return
+				}
+			}
+		}
+	}()
+	return s, nil
+}
+
+// Heads delivers new block headers; it is closed when the subscription
+// ends, e.g. because the connection dropped.
+func (s *HeadSubscription) Heads() <-chan *model.BlockRef { return s.heads }
+
+// Close ends the subscription and the connection.
+func (s *HeadSubscription) Close() {
+	close(s.done)
+	s.sub.Unsubscribe()
+	s.rpc.Close()
+}
@@ -0,0 +1,109 @@
+package onchain
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"fmt"
+	"math/big"
+	"os"
+	"sync"
+
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/accounts/keystore"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/ethclient"
+)
+
+// Transactor signs and sends transactions from a single server-side account
+// loaded from an encrypted keystore file. It is only meant for operational
+// wallets such as the testnet faucet; user funds are never signed here.
+type Transactor struct {
+	rpc     *ethclient.Client
+	key     *ecdsa.PrivateKey
+	from    common.Address
+	chainID *big.Int
+
+	// mu serializes nonce assignment across concurrent sends.
+	mu sync.Mutex
+}
+
+// NewTransactor decrypts the keystore at keystorePath and binds it to the
+// RPC connection of c.
+func NewTransactor(ctx context.Context, c *EthClient, keystorePath, password string) (*Transactor, error) {
+	bz, err := os.ReadFile(keystorePath)
+	if err != nil {
+		return nil, fmt.Errorf("read keystore: %w", err)
+	}
+	key, err := keystore.DecryptKey(bz, password)
+	if err != nil {
+		return nil, fmt.Errorf("decrypt keystore: %w", err)
+	}
+
+	chainID, err := c.rpc.ChainID(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("get chain id: %w", err)
+	}
+
+	return &Transactor{
+		rpc:     c.rpc,
+		key:     key.PrivateKey,
+		from:    key.Address,
+		chainID: chainID,
+	}, nil
+}
+
+// From returns the sending account.
+func (t *Transactor) From() common.Address {
+	return t.from
+}
+
+// Send signs and broadcasts a legacy transaction and returns its hash without
+// waiting for it to be mined.
+func (t *Transactor) Send(ctx context.Context, to common.Address, value *big.Int, data []byte) (common.Hash, error) {
+	if value == nil {
+		value = new(big.Int)
+	}
+
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	nonce, err := t.rpc.PendingNonceAt(ctx, t.from)
+	if err != nil {
+		return common.Hash{}, fmt.Errorf("get nonce: %w", err)
+	}
+	gasPrice, err := t.rpc.SuggestGasPrice(ctx)
+	if err != nil {
+		return common.Hash{}, fmt.Errorf("suggest gas price: %w", err)
+	}
+	gas, err := t.rpc.EstimateGas(ctx, ethereum.CallMsg{
+		From:     t.from,
+		To:       &to,
+		GasPrice: gasPrice,
+		Value:    value,
+		Data:     data,
+	})
+	if err != nil {
+		return common.Hash{}, fmt.Errorf("estimate gas: %w", err)
+	}
+
+	tx := types.NewTx(&types.LegacyTx{
+		Nonce:    nonce,
+		GasPrice: gasPrice,
+		Gas:      gas + gas/5, // 20% headroom over the estimate
+		To:       &to,
+		Value:    value,
+		Data:     data,
+	})
+	signed, err := types.SignTx(tx, types.LatestSignerForChainID(t.chainID), t.key)
+	if err != nil {
+		return common.Hash{}, fmt.Errorf("sign tx: %w", err)
+	}
+	sendCtx, done := startRPC(ctx, "eth_sendRawTransaction", selectorName(data))
+	err = t.rpc.SendTransaction(sendCtx, signed)
+	done(err)
+	if err != nil {
+		return common.Hash{}, fmt.Errorf("send tx: %w", err)
+	}
+	return signed.Hash(), nil
+}
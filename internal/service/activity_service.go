@@ -0,0 +1,96 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/cina_dex_backend/internal/model"
+	"github.com/ethereum/go-ethereum/common"
+)
+
+// maxActivityPageSize bounds the page size of history queries.
+const maxActivityPageSize = 100
+
+// activityTypes lists the event types accepted as history filters.
+var activityTypes = map[string]bool{
+	model.ActivityDeposit:    true,
+	model.ActivityWithdraw:   true,
+	model.ActivityBorrow:     true,
+	model.ActivityRepay:      true,
+	model.ActivityLiquidate:  true,
+	model.ActivityFaucetMint: true,
+}
+
+// ActivityService serves per-user protocol activity from indexed logs.
+type ActivityService interface {
+	// UserHistory returns the activity of address, newest first. types
+	// filters by event type; empty means all types. page starts at 1.
+	UserHistory(ctx context.Context, address string, types []string, page, pageSize int) (*model.ActivityPage, error)
+}
+
+type activityService struct {
+	indexer *EventIndexer
+}
+
+// NewActivityService constructs an ActivityService backed by the event indexer.
+func NewActivityService(indexer *EventIndexer) ActivityService {
+	return &activityService{indexer: indexer}
+}
+
+func (s *activityService) UserHistory(ctx context.Context, address string, types []string, page, pageSize int) (*model.ActivityPage, error) {
+	if !isHexAddress(address) {
+		return nil, fmt.Errorf("invalid address: %s", address)
+	}
+	if page < 1 {
+		return nil, fmt.Errorf("page must be >= 1")
+	}
+	if pageSize < 1 || pageSize > maxActivityPageSize {
+		return nil, fmt.Errorf("pageSize must be between 1 and %d", maxActivityPageSize)
+	}
+	want := make(map[string]bool, len(types))
+	for _, t := range types {
+		if !activityTypes[t] {
+			return nil, fmt.Errorf("unknown activity type %q", t)
+		}
+		want[t] = true
+	}
+
+	addr := common.HexToAddress(address).Hex()
+	all := s.indexer.AccountEvents(addr)
+
+	matched := make([]*model.ActivityEvent, 0, len(all))
+	for i := len(all) - 1; i >= 0; i-- {
+		ev := all[i]
+		if len(want) > 0 && !want[ev.Type] {
+			continue
+		}
+		if ev.Type == model.ActivityLiquidate {
+			// copy so the shared indexed event is not mutated
+			cp := *ev
+			cp.Role = "borrower"
+			if strings.EqualFold(ev.Liquidator, addr) {
+				cp.Role = "liquidator"
+			}
+			ev = &cp
+		}
+		matched = append(matched, ev)
+	}
+
+	out := &model.ActivityPage{
+		Address:      addr,
+		Items:        []*model.ActivityEvent{},
+		Total:        len(matched),
+		Page:         page,
+		PageSize:     pageSize,
+		IndexedBlock: s.indexer.IndexedBlock(),
+	}
+	if start := (page - 1) * pageSize; start < len(matched) {
+		end := start + pageSize
+		if end > len(matched) {
+			end = len(matched)
+		}
+		out.Items = matched[start:end]
+	}
+	return out, nil
+}
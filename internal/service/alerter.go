@@ -0,0 +1,83 @@
+package service
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"time"
+
+	"github.com/cina_dex_backend/internal/model"
+)
+
+// Alerter delivers alerts raised by background monitors.
+type Alerter interface {
+	Alert(ctx context.Context, alert *model.Alert) error
+}
+
+// NewAlerter returns an Alerter that always logs and, when webhookURL is
+// set, also POSTs every alert as JSON to it.
+func NewAlerter(webhookURL string) Alerter {
+	alerters := []Alerter{logAlerter{}}
+	if webhookURL != "" {
+		alerters = append(alerters, &webhookAlerter{
+			url:    webhookURL,
+			client: &http.Client{Timeout: 10 * time.Second},
+		})
+	}
+	return multiAlerter(alerters)
+}
+
+type logAlerter struct{}
+
+func (logAlerter) Alert(ctx context.Context, alert *model.Alert) error {
+	level := slog.LevelWarn
+	if alert.Level == "info" {
+		level = slog.LevelInfo
+	}
+	slog.Log(ctx, level, "alert: "+alert.Message, "source", alert.Source, "alert_level", alert.Level)
+	return nil
+}
+
+// webhookAlerter posts alerts to an HTTP endpoint (e.g. a chat-ops bridge).
+type webhookAlerter struct {
+	url    string
+	client *http.Client
+}
+
+func (a *webhookAlerter) Alert(ctx context.Context, alert *model.Alert) error {
+	body, err := json.Marshal(alert)
+	if err != nil {
+		return fmt.Errorf("marshal alert: %w", err)
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, a.url, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := a.client.Do(req)
+	if err != nil {
+		return fmt.Errorf("post alert: %w", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode >= 300 {
+		return fmt.Errorf("post alert: unexpected status %s", resp.Status)
+	}
+	return nil
+}
+
+// multiAlerter fans an alert out to every alerter and returns the first error.
+type multiAlerter []Alerter
+
+func (m multiAlerter) Alert(ctx context.Context, alert *model.Alert) error {
+	var first error
+	for _, a := range m {
+		if err := a.Alert(ctx, alert); err != nil && first == nil {
+			first = err
+		}
+	}
+	return first
+}
@@ -0,0 +1,294 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/cina_dex_backend/internal/config"
+	"github.com/cina_dex_backend/internal/health"
+	"github.com/cina_dex_backend/internal/lifecycle"
+	"github.com/cina_dex_backend/internal/model"
+	"github.com/cina_dex_backend/internal/onchain"
+	"github.com/cina_dex_backend/internal/store"
+	"github.com/cina_dex_backend/internal/tracing"
+)
+
+// EventIndexer follows LendingPool / MockUSDT logs from a start block and
+// keeps the decoded activity in memory, indexed by account.
+//
+// Indexed ranges are persisted as one JSON line per batch so that a restart
+// resumes from the last indexed block instead of rescanning the chain.
+type EventIndexer struct {
+	client        onchain.Client
+	batchLog      *store.AppendLog
+	startBlock    uint64
+	chunkSize     uint64
+	confirmations uint64
+
+	// syncMu serializes Sync calls and guards the loan check below.
+	syncMu sync.Mutex
+	// pendingLoans is nextLoanId as read at a head block, compared with the
+	// Borrow events indexed once that block is confirmed.
+	pendingLoans *loanCheck
+	checkedLoans *loanCheck
+
+	mu        sync.RWMutex
+	started   bool
+	next      uint64 // next block to index
+	persisted uint64 // next block as of the last persisted batch
+	events    []*model.ActivityEvent
+	byAccount map[string][]*model.ActivityEvent
+	seen      map[string]struct{}
+	// borrowedLoans is one past the highest loan id seen in a Borrow event.
+	borrowedLoans uint64
+}
+
+// loanCheck is nextLoanId as read at a head block.
+type loanCheck struct {
+	head       uint64
+	nextLoanID uint64
+}
+
+// eventBatch is the persisted record of an indexed block range [From, To].
+type eventBatch struct {
+	From   uint64                 `json:"from"`
+	To     uint64                 `json:"to"`
+	Events []*model.ActivityEvent `json:"events,omitempty"`
+}
+
+// NewEventIndexer opens the persisted event batches under cfg.DataDir. It
+// fails if the contract ABIs in cfg.Events.ABIDir are missing
+// (onchain.ErrABINotFound) or disagree with the event decoder, since
+// unverified signatures could decode every log wrongly.
+func NewEventIndexer(cfg *config.Config, c onchain.Client) (*EventIndexer, error) {
+	if err := onchain.VerifyEventABI(cfg.Events.ABIDir, cfg.ChainConfig.MockUSDT != ""); err != nil {
+		return nil, err
+	}
+
+	batchLog, err := store.OpenAppendLog(cfg.DataPath("events.jsonl"))
+	if err != nil {
+		return nil, err
+	}
+
+	x := &EventIndexer{
+		client:        c,
+		batchLog:      batchLog,
+		startBlock:    cfg.Events.StartBlock,
+		chunkSize:     cfg.Events.ChunkSize,
+		confirmations: cfg.Events.Confirmations,
+		byAccount:     make(map[string][]*model.ActivityEvent),
+		seen:          make(map[string]struct{}),
+	}
+
+	if err := batchLog.Replay(func(line []byte) error {
+		var b eventBatch
+		if err := json.Unmarshal(line, &b); err != nil {
+			return fmt.Errorf("decode event batch: %w", err)
+		}
+		x.apply(&b)
+		return nil
+	}); err != nil {
+		return nil, err
+	}
+	x.persisted = x.next
+
+	return x, nil
+}
+
+// Sync indexes every block up to head - confirmations, chunkSize blocks at a time.
+func (x *EventIndexer) Sync(ctx context.Context) (err error) {
+	ctx, span := tracing.Start(ctx, "EventIndexer.Sync")
+	defer func() { tracing.End(span, err) }()
+
+	x.syncMu.Lock()
+	defer x.syncMu.Unlock()
+
+	head, err := x.client.HeadBlock(ctx)
+	if err != nil {
+		return err
+	}
+	if head.Number < x.confirmations {
+		return nil
+	}
+	safe := head.Number - x.confirmations
+
+	x.mu.RLock()
+	started, next := x.started, x.next
+	x.mu.RUnlock()
+
+	if !started {
+		next = x.startBlock
+		if next == 0 {
+			// Without a configured start block only new activity is indexed.
+			slog.InfoContext(ctx, "event indexer: no start block configured, indexing from head", "block", safe)
+			next = safe
+		}
+		// Persist the start as an empty range so restarts keep the same
+		// history range.
+		if err := x.record(&eventBatch{From: next, To: next - 1}); err != nil {
+			return err
+		}
+	}
+
+	for next <= safe {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		to := next + x.chunkSize - 1
+		if to > safe {
+			to = safe
+		}
+
+		events, err := x.client.GetActivityEvents(ctx, next, to)
+		if err != nil {
+			return fmt.Errorf("index blocks %d-%d: %w", next, to, err)
+		}
+
+		b := &eventBatch{From: next, To: to, Events: events}
+		// Empty ranges are only persisted once they add up to a full chunk;
+		// at worst they are rescanned after a restart.
+		if len(events) > 0 || to+1-x.persisted >= x.chunkSize {
+			if err := x.record(b); err != nil {
+				return err
+			}
+		} else {
+			x.apply(b)
+		}
+		next = to + 1
+	}
+	x.checkLoans(ctx, head.Number, safe)
+	return nil
+}
+
+// checkLoans warns when nextLoanId grew over an indexed, confirmed range
+// whose newest loan has no Borrow event, which means the event signatures no
+// longer match the contract and history is silently incomplete. Callers hold
+// syncMu.
+func (x *EventIndexer) checkLoans(ctx context.Context, head, safe uint64) {
+	x.mu.RLock()
+	borrowed := x.borrowedLoans
+	x.mu.RUnlock()
+
+	if p := x.pendingLoans; p != nil && safe >= p.head {
+		if prev := x.checkedLoans; prev != nil && p.nextLoanID > prev.nextLoanID && borrowed < p.nextLoanID {
+			slog.WarnContext(ctx, "event indexer: loans were created but their Borrow events were not indexed; event signatures may not match the contract",
+				"from_block", prev.head, "to_block", p.head, "next_loan_id", p.nextLoanID, "indexed_loans", borrowed)
+		}
+		x.checkedLoans, x.pendingLoans = p, nil
+	}
+	if x.pendingLoans == nil {
+		n, err := x.client.NextLoanID(ctx)
+		if err != nil {
+			return
+		}
+		x.pendingLoans = &loanCheck{head: head, nextLoanID: n}
+	}
+}
+
+// record persists a batch and applies it.
+func (x *EventIndexer) record(b *eventBatch) error {
+	if err := x.batchLog.Append(b); err != nil {
+		return fmt.Errorf("record event batch: %w", err)
+	}
+	x.apply(b)
+
+	x.mu.Lock()
+	x.persisted = x.next
+	x.mu.Unlock()
+	return nil
+}
+
+// apply adds a batch's events, skipping any already indexed, and advances the cursor.
+func (x *EventIndexer) apply(b *eventBatch) {
+	x.mu.Lock()
+	defer x.mu.Unlock()
+
+	if !x.started {
+		x.started = true
+		x.next = b.From
+	}
+	for _, ev := range b.Events {
+		key := fmt.Sprintf("%s#%d", ev.TxHash, ev.LogIndex)
+		if _, dup := x.seen[key]; dup {
+			continue
+		}
+		x.seen[key] = struct{}{}
+		x.events = append(x.events, ev)
+		if ev.Type == model.ActivityBorrow && ev.LoanID != nil && *ev.LoanID+1 > x.borrowedLoans {
+			x.borrowedLoans = *ev.LoanID + 1
+		}
+
+		account := strings.ToLower(ev.Account)
+		x.byAccount[account] = append(x.byAccount[account], ev)
+		if liq := strings.ToLower(ev.Liquidator); liq != "" && liq != account {
+			x.byAccount[liq] = append(x.byAccount[liq], ev)
+		}
+	}
+	if b.To+1 > x.next {
+		x.next = b.To + 1
+	}
+}
+
+// AccountEvents returns the events where address is the account or the
+// liquidator, oldest first.
+func (x *EventIndexer) AccountEvents(address string) []*model.ActivityEvent {
+	x.mu.RLock()
+	defer x.mu.RUnlock()
+	return append([]*model.ActivityEvent(nil), x.byAccount[strings.ToLower(address)]...)
+}
+
+// Events returns every indexed event, oldest first.
+func (x *EventIndexer) Events() []*model.ActivityEvent {
+	x.mu.RLock()
+	defer x.mu.RUnlock()
+	return append([]*model.ActivityEvent(nil), x.events...)
+}
+
+// IndexedBlock returns the last indexed block, 0 before the first sync.
+func (x *EventIndexer) IndexedBlock() uint64 {
+	x.mu.RLock()
+	defer x.mu.RUnlock()
+	if x.next == 0 {
+		return 0
+	}
+	return x.next - 1
+}
+
+// StartEventIndexer launches a background goroutine that syncs the indexer
+// every interval.
+func StartEventIndexer(ctx context.Context, workers *lifecycle.Workers, x *EventIndexer, interval time.Duration) {
+	if x == nil || interval <= 0 {
+		return
+	}
+	health.Register("event_indexer", 3*interval, false)
+
+	workers.Go("event indexer", func() {
+		syncEventsOnce(ctx, x)
+
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-ctx.Done():
+				slog.Info("event indexer stopped: context cancelled")
+				return
+			case <-ticker.C:
+				syncEventsOnce(ctx, x)
+			}
+		}
+	})
+}
+
+func syncEventsOnce(ctx context.Context, x *EventIndexer) {
+	err := x.Sync(ctx)
+	jobDone("event_indexer", err)
+	if err != nil {
+		slog.ErrorContext(ctx, "event indexer: sync", "err", err)
+	}
+}
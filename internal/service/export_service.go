@@ -0,0 +1,357 @@
+package service
+
+import (
+	"bufio"
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"math/big"
+	"strconv"
+	"time"
+
+	"github.com/cina_dex_backend/internal/model"
+	"github.com/ethereum/go-ethereum/common"
+)
+
+// Export formats accepted by ExportService.
+const (
+	ExportFormatCSV  = "csv"
+	ExportFormatJSON = "json"
+)
+
+// ErrInvalidExport is returned for an unsupported format or a malformed
+// address.
+var ErrInvalidExport = errors.New("invalid export request")
+
+// ValidExportFormat reports whether format is one of the Export formats.
+func ValidExportFormat(format string) bool {
+	return exportFormats[format]
+}
+
+// Token decimals used for display amounts.
+const (
+	usdtDecimals   = 6
+	nativeDecimals = 18
+	fTokenDecimals = 18
+)
+
+// ExportService streams accounting records as CSV or JSON. Arguments are
+// validated before anything is written, so an error returned without output
+// can still be reported to the client.
+type ExportService interface {
+	// ExportUser writes the indexed activity of address in [from, to].
+	ExportUser(ctx context.Context, w io.Writer, address, format string, from, to time.Time) error
+	// ExportEvents writes every indexed pool event in [from, to].
+	ExportEvents(ctx context.Context, w io.Writer, format string, from, to time.Time) error
+	// ExportLoans writes every loan started in [from, to].
+	ExportLoans(ctx context.Context, w io.Writer, format string, from, to time.Time) error
+}
+
+type exportService struct {
+	events *EventIndexer
+	loans  *LoanScanner
+	prices PriceHistoryService
+}
+
+// NewExportService constructs an ExportService. prices values amounts in USD
+// at event time and may be nil.
+func NewExportService(events *EventIndexer, loans *LoanScanner, prices PriceHistoryService) ExportService {
+	return &exportService{
+		events: events,
+		loans:  loans,
+		prices: prices,
+	}
+}
+
+func (s *exportService) ExportUser(ctx context.Context, w io.Writer, address, format string, from, to time.Time) error {
+	if !isHexAddress(address) {
+		return fmt.Errorf("%w: address %s", ErrInvalidExport, address)
+	}
+	return s.exportEvents(ctx, w, format, from, to, s.events.AccountEvents(common.HexToAddress(address).Hex()))
+}
+
+func (s *exportService) ExportEvents(ctx context.Context, w io.Writer, format string, from, to time.Time) error {
+	return s.exportEvents(ctx, w, format, from, to, s.events.Events())
+}
+
+func (s *exportService) exportEvents(ctx context.Context, w io.Writer, format string, from, to time.Time, events []*model.ActivityEvent) error {
+	out, err := newExportWriter(w, format, eventExportHeader)
+	if err != nil {
+		return err
+	}
+	for _, ev := range events {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		t := time.Unix(int64(ev.BlockTime), 0)
+		if t.Before(from) || t.After(to) {
+			continue
+		}
+		if err := out.write(s.eventRow(ev, t)); err != nil {
+			return err
+		}
+	}
+	return out.close()
+}
+
+func (s *exportService) ExportLoans(ctx context.Context, w io.Writer, format string, from, to time.Time) error {
+	if s.loans == nil {
+		return fmt.Errorf("loan scanner not configured")
+	}
+	if !exportFormats[format] {
+		return fmt.Errorf("%w: format %q", ErrInvalidExport, format)
+	}
+	loans, err := s.loans.Loans(ctx)
+	if err != nil {
+		return err
+	}
+
+	out, err := newExportWriter(w, format, loanExportHeader)
+	if err != nil {
+		return err
+	}
+	for _, loan := range loans {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		t := time.Unix(int64(loan.StartTime), 0)
+		if t.Before(from) || t.After(to) {
+			continue
+		}
+		if err := out.write(s.loanRow(loan, t)); err != nil {
+			return err
+		}
+	}
+	return out.close()
+}
+
+// eventExportRow is one exported event. Raw amounts are in smallest units,
+// display amounts in whole tokens; USD values use the oracle price recorded
+// at or before the event.
+type eventExportRow struct {
+	Time          string `json:"time"`
+	BlockNumber   uint64 `json:"blockNumber"`
+	TxHash        string `json:"txHash"`
+	LogIndex      uint   `json:"logIndex"`
+	Type          string `json:"type"`
+	Account       string `json:"account"`
+	Liquidator    string `json:"liquidator"`
+	LoanID        string `json:"loanId"`
+	AmountRaw     string `json:"amountRaw"`
+	AmountUSDT    string `json:"amountUsdt"`
+	FTokenRaw     string `json:"fTokenRaw"`
+	FToken        string `json:"fToken"`
+	CollateralRaw string `json:"collateralRaw"`
+	CollateralBNB string `json:"collateralBnb"`
+	BNBPriceUSD   string `json:"bnbPriceUsd"`
+	CollateralUSD string `json:"collateralUsd"`
+	AmountUSD     string `json:"amountUsd"`
+}
+
+var eventExportHeader = []string{
+	"time", "block_number", "tx_hash", "log_index", "type", "account", "liquidator", "loan_id",
+	"amount_raw", "amount_usdt", "ftoken_raw", "ftoken", "collateral_raw", "collateral_bnb",
+	"bnb_price_usd", "collateral_usd", "amount_usd",
+}
+
+func (r *eventExportRow) record() []string {
+	return []string{
+		r.Time, strconv.FormatUint(r.BlockNumber, 10), r.TxHash, strconv.FormatUint(uint64(r.LogIndex), 10),
+		r.Type, r.Account, r.Liquidator, r.LoanID,
+		r.AmountRaw, r.AmountUSDT, r.FTokenRaw, r.FToken, r.CollateralRaw, r.CollateralBNB,
+		r.BNBPriceUSD, r.CollateralUSD, r.AmountUSD,
+	}
+}
+
+func (s *exportService) eventRow(ev *model.ActivityEvent, t time.Time) *eventExportRow {
+	row := &eventExportRow{
+		Time:          t.UTC().Format(time.RFC3339),
+		BlockNumber:   ev.BlockNumber,
+		TxHash:        ev.TxHash,
+		LogIndex:      ev.LogIndex,
+		Type:          ev.Type,
+		Account:       ev.Account,
+		Liquidator:    ev.Liquidator,
+		AmountRaw:     ev.Amount,
+		AmountUSDT:    formatUnits(ev.Amount, usdtDecimals),
+		FTokenRaw:     ev.FTokenAmount,
+		FToken:        formatUnits(ev.FTokenAmount, fTokenDecimals),
+		CollateralRaw: ev.CollateralAmount,
+		CollateralBNB: formatUnits(ev.CollateralAmount, nativeDecimals),
+		// USDT is valued at 1 USD.
+		AmountUSD: formatUnits(ev.Amount, usdtDecimals),
+	}
+	if ev.LoanID != nil {
+		row.LoanID = strconv.FormatUint(*ev.LoanID, 10)
+	}
+	if price, ok := s.priceAt(t); ok {
+		row.BNBPriceUSD = formatUnits(price.String(), nativeDecimals)
+		if coll, err := parseBig(ev.CollateralAmount); err == nil {
+			row.CollateralUSD = formatUnits(collateralValueUSD(coll, price).String(), nativeDecimals)
+		}
+	}
+	return row
+}
+
+// loanExportRow is one exported loan, valued at its start time.
+type loanExportRow struct {
+	LoanID        uint64 `json:"loanId"`
+	Borrower      string `json:"borrower"`
+	StartTime     string `json:"startTime"`
+	MaturityTime  string `json:"maturityTime"`
+	Duration      uint64 `json:"duration"`
+	IsActive      bool   `json:"isActive"`
+	PrincipalRaw  string `json:"principalRaw"`
+	PrincipalUSDT string `json:"principalUsdt"`
+	RepaymentRaw  string `json:"repaymentRaw"`
+	RepaymentUSDT string `json:"repaymentUsdt"`
+	InterestUSDT  string `json:"interestUsdt"`
+	CollateralRaw string `json:"collateralRaw"`
+	CollateralBNB string `json:"collateralBnb"`
+	BNBPriceUSD   string `json:"bnbPriceUsd"`
+	CollateralUSD string `json:"collateralUsd"`
+}
+
+var loanExportHeader = []string{
+	"loan_id", "borrower", "start_time", "maturity_time", "duration", "is_active",
+	"principal_raw", "principal_usdt", "repayment_raw", "repayment_usdt", "interest_usdt",
+	"collateral_raw", "collateral_bnb", "bnb_price_usd", "collateral_usd",
+}
+
+func (r *loanExportRow) record() []string {
+	return []string{
+		strconv.FormatUint(r.LoanID, 10), r.Borrower, r.StartTime, r.MaturityTime,
+		strconv.FormatUint(r.Duration, 10), strconv.FormatBool(r.IsActive),
+		r.PrincipalRaw, r.PrincipalUSDT, r.RepaymentRaw, r.RepaymentUSDT, r.InterestUSDT,
+		r.CollateralRaw, r.CollateralBNB, r.BNBPriceUSD, r.CollateralUSD,
+	}
+}
+
+func (s *exportService) loanRow(loan *model.Loan, t time.Time) *loanExportRow {
+	row := &loanExportRow{
+		LoanID:        loan.ID,
+		Borrower:      loan.Borrower,
+		StartTime:     t.UTC().Format(time.RFC3339),
+		MaturityTime:  time.Unix(int64(loan.StartTime+loan.Duration), 0).UTC().Format(time.RFC3339),
+		Duration:      loan.Duration,
+		IsActive:      loan.IsActive,
+		PrincipalRaw:  loan.Principal,
+		PrincipalUSDT: formatUnits(loan.Principal, usdtDecimals),
+		RepaymentRaw:  loan.RepaymentAmount,
+		RepaymentUSDT: formatUnits(loan.RepaymentAmount, usdtDecimals),
+		CollateralRaw: loan.CollateralAmount,
+		CollateralBNB: formatUnits(loan.CollateralAmount, nativeDecimals),
+	}
+	principal, err1 := parseBig(loan.Principal)
+	repayment, err2 := parseBig(loan.RepaymentAmount)
+	if err1 == nil && err2 == nil {
+		row.InterestUSDT = formatUnits(new(big.Int).Sub(repayment, principal).String(), usdtDecimals)
+	}
+	if price, ok := s.priceAt(t); ok {
+		row.BNBPriceUSD = formatUnits(price.String(), nativeDecimals)
+		if coll, err := parseBig(loan.CollateralAmount); err == nil {
+			row.CollateralUSD = formatUnits(collateralValueUSD(coll, price).String(), nativeDecimals)
+		}
+	}
+	return row
+}
+
+func (s *exportService) priceAt(t time.Time) (*big.Int, bool) {
+	if s.prices == nil {
+		return nil, false
+	}
+	return s.prices.PriceAt(PriceSourceOracle, t)
+}
+
+// formatUnits renders a raw integer amount with the given decimals, trimming
+// trailing zeros, e.g. ("1500000", 6) -> "1.5". Empty or invalid input gives "".
+func formatUnits(raw string, decimals int) string {
+	v, err := parseBig(raw)
+	if err != nil {
+		return ""
+	}
+	r := new(big.Rat).SetFrac(v, new(big.Int).Exp(big.NewInt(10), big.NewInt(int64(decimals)), nil))
+	out := r.FloatString(decimals)
+	if decimals > 0 {
+		for out[len(out)-1] == '0' {
+			out = out[:len(out)-1]
+		}
+		if out[len(out)-1] == '.' {
+			out = out[:len(out)-1]
+		}
+	}
+	return out
+}
+
+// exportRow is a record that can be written as a CSV line or a JSON object.
+type exportRow interface {
+	record() []string
+}
+
+var exportFormats = map[string]bool{ExportFormatCSV: true, ExportFormatJSON: true}
+
+// exportWriter streams rows without holding the whole export in memory.
+type exportWriter struct {
+	format string
+	buf    *bufio.Writer
+	csv    *csv.Writer
+	rows   int
+}
+
+// newExportWriter validates format and writes the CSV header or the opening
+// bracket of the JSON array.
+func newExportWriter(w io.Writer, format string, header []string) (*exportWriter, error) {
+	if !exportFormats[format] {
+		return nil, fmt.Errorf("%w: format %q", ErrInvalidExport, format)
+	}
+	out := &exportWriter{format: format, buf: bufio.NewWriter(w)}
+	if format == ExportFormatCSV {
+		out.csv = csv.NewWriter(out.buf)
+		if err := out.csv.Write(header); err != nil {
+			return nil, err
+		}
+		return out, nil
+	}
+	if _, err := out.buf.WriteString("["); err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (e *exportWriter) write(row exportRow) error {
+	defer func() { e.rows++ }()
+	if e.format == ExportFormatCSV {
+		return e.csv.Write(row.record())
+	}
+
+	bz, err := json.Marshal(row)
+	if err != nil {
+		return err
+	}
+	if e.rows > 0 {
+		if err := e.buf.WriteByte(','); err != nil {
+			return err
+		}
+	}
+	if err := e.buf.WriteByte('\n'); err != nil {
+		return err
+	}
+	_, err = e.buf.Write(bz)
+	return err
+}
+
+// close terminates the output and flushes buffered rows.
+func (e *exportWriter) close() error {
+	if e.format == ExportFormatCSV {
+		e.csv.Flush()
+		if err := e.csv.Error(); err != nil {
+			return err
+		}
+	} else if _, err := e.buf.WriteString("\n]\n"); err != nil {
+		return err
+	}
+	return e.buf.Flush()
+}
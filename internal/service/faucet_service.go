@@ -0,0 +1,240 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"math/big"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/cina_dex_backend/internal/config"
+	"github.com/cina_dex_backend/internal/model"
+	"github.com/cina_dex_backend/internal/onchain"
+	"github.com/cina_dex_backend/internal/store"
+	"github.com/ethereum/go-ethereum/common"
+)
+
+// ErrFaucetRateLimited is returned when an address or IP exceeded its faucet quota.
+var ErrFaucetRateLimited = errors.New("faucet rate limited")
+
+// FaucetService mints MockUSDT (and optionally drips BNB) to testnet users,
+// signing server-side so the MockUSDT owner key never leaves the backend.
+type FaucetService interface {
+	// Claim pays out one grant to address, enforcing per-address and per-IP quotas.
+	Claim(ctx context.Context, address, ip string) (*model.FaucetGrant, error)
+	// Status reports whether address may claim now and its recent grants.
+	Status(ctx context.Context, address string) (*model.FaucetStatus, error)
+}
+
+// faucetService is the default implementation of FaucetService.
+type faucetService struct {
+	cfg        config.FaucetConfig
+	sender     *onchain.Transactor
+	tokenAddr  common.Address
+	usdtAmount *big.Int
+	bnbAmount  *big.Int
+	grantLog   *store.AppendLog
+
+	// claimMu serializes claims so quota checks and sends cannot race.
+	claimMu sync.Mutex
+	mu      sync.RWMutex
+	byAddr  map[string][]*model.FaucetGrant
+	byIP    map[string][]*model.FaucetGrant
+}
+
+// NewFaucetService constructs a FaucetService. It refuses to run unless the
+// current chain has a MockUSDT address, i.e. only on testnet.
+func NewFaucetService(cfg *config.Config, sender *onchain.Transactor, grantLog *store.AppendLog) (FaucetService, error) {
+	if cfg.ChainConfig.MockUSDT == "" {
+		return nil, fmt.Errorf("faucet requires mockUsdt in chain config (testnet only)")
+	}
+	if !isHexAddress(cfg.ChainConfig.MockUSDT) {
+		return nil, fmt.Errorf("invalid mockUsdt address in chain config: %s", cfg.ChainConfig.MockUSDT)
+	}
+	if sender == nil {
+		return nil, fmt.Errorf("faucet requires a signer")
+	}
+
+	usdtAmount, err := parseBig(cfg.Faucet.USDTAmount)
+	if err != nil || usdtAmount.Sign() <= 0 {
+		return nil, fmt.Errorf("invalid FAUCET_USDT_AMOUNT: %s", cfg.Faucet.USDTAmount)
+	}
+	bnbAmount, err := parseBig(cfg.Faucet.BNBAmount)
+	if err != nil || bnbAmount.Sign() < 0 {
+		return nil, fmt.Errorf("invalid FAUCET_BNB_AMOUNT: %s", cfg.Faucet.BNBAmount)
+	}
+	if cfg.Faucet.AddressLimit <= 0 || cfg.Faucet.IPLimit <= 0 {
+		return nil, fmt.Errorf("faucet address/ip limits must be positive")
+	}
+
+	s := &faucetService{
+		cfg:        cfg.Faucet,
+		sender:     sender,
+		tokenAddr:  common.HexToAddress(cfg.ChainConfig.MockUSDT),
+		usdtAmount: usdtAmount,
+		bnbAmount:  bnbAmount,
+		grantLog:   grantLog,
+		byAddr:     make(map[string][]*model.FaucetGrant),
+		byIP:       make(map[string][]*model.FaucetGrant),
+	}
+
+	// Rebuild quotas from previously recorded grants.
+	if err := grantLog.Replay(func(line []byte) error {
+		var g model.FaucetGrant
+		if err := json.Unmarshal(line, &g); err != nil {
+			return fmt.Errorf("decode faucet grant: %w", err)
+		}
+		s.index(&g)
+		return nil
+	}); err != nil {
+		return nil, err
+	}
+
+	return s, nil
+}
+
+// Claim mints usdtAmount MockUSDT to address and, if configured, sends bnbAmount wei.
+func (s *faucetService) Claim(ctx context.Context, address, ip string) (*model.FaucetGrant, error) {
+	if !isHexAddress(address) {
+		return nil, fmt.Errorf("invalid address: %s", address)
+	}
+	to := common.HexToAddress(address)
+	addrKey := strings.ToLower(to.Hex())
+
+	s.claimMu.Lock()
+	defer s.claimMu.Unlock()
+
+	now := time.Now()
+	if next, ok := s.nextAllowed(s.byAddr[addrKey], s.cfg.AddressLimit, now); !ok {
+		return nil, fmt.Errorf("%w: address can claim again at %s", ErrFaucetRateLimited, next.UTC().Format(time.RFC3339))
+	}
+	if ip != "" {
+		if next, ok := s.nextAllowed(s.byIP[ip], s.cfg.IPLimit, now); !ok {
+			return nil, fmt.Errorf("%w: ip can claim again at %s", ErrFaucetRateLimited, next.UTC().Format(time.RFC3339))
+		}
+	}
+
+	// mint(address to, uint256 amount)
+	data := make([]byte, len(selectorMockUSDTMint)+64)
+	copy(data, selectorMockUSDTMint)
+	copy(data[len(selectorMockUSDTMint):], packAddress(to))
+	copy(data[len(selectorMockUSDTMint)+32:], packUint256(s.usdtAmount))
+
+	mintHash, err := s.sender.Send(ctx, s.tokenAddr, nil, data)
+	if err != nil {
+		return nil, fmt.Errorf("send mint tx: %w", err)
+	}
+
+	grant := &model.FaucetGrant{
+		Address:    to.Hex(),
+		IP:         ip,
+		USDTAmount: s.usdtAmount.String(),
+		USDTTxHash: mintHash.Hex(),
+		GrantedAt:  now.Unix(),
+	}
+
+	if s.bnbAmount.Sign() > 0 {
+		// The mint already went out, so a failed drip still counts as a grant.
+		if bnbHash, err := s.sender.Send(ctx, to, s.bnbAmount, nil); err != nil {
+			slog.ErrorContext(ctx, "faucet: send bnb drip", "to", to.Hex(), "err", err)
+		} else {
+			grant.BNBAmount = s.bnbAmount.String()
+			grant.BNBTxHash = bnbHash.Hex()
+		}
+	}
+
+	s.index(grant)
+	if err := s.grantLog.Append(grant); err != nil {
+		slog.ErrorContext(ctx, "faucet: record grant", "err", err)
+	}
+
+	return grant, nil
+}
+
+// Status reports quota state for address without claiming.
+func (s *faucetService) Status(ctx context.Context, address string) (*model.FaucetStatus, error) {
+	if !isHexAddress(address) {
+		return nil, fmt.Errorf("invalid address: %s", address)
+	}
+	to := common.HexToAddress(address)
+
+	s.mu.RLock()
+	grants := append([]*model.FaucetGrant(nil), s.byAddr[strings.ToLower(to.Hex())]...)
+	s.mu.RUnlock()
+
+	now := time.Now()
+	next, ok := s.nextAllowed(grants, s.cfg.AddressLimit, now)
+
+	status := &model.FaucetStatus{
+		Address:    to.Hex(),
+		CanClaim:   ok,
+		USDTAmount: s.usdtAmount.String(),
+		BNBAmount:  s.bnbAmount.String(),
+		Grants:     withinWindow(grants, now.Add(-s.cfg.Window)),
+	}
+	if !ok {
+		status.NextClaim = next.Unix()
+	}
+	return status, nil
+}
+
+// nextAllowed checks grants (oldest first) against the cooldown and the
+// per-window limit. It returns the earliest time a new grant is allowed and
+// whether that time has already passed.
+func (s *faucetService) nextAllowed(grants []*model.FaucetGrant, limit int, now time.Time) (time.Time, bool) {
+	next := now
+
+	if n := len(grants); n > 0 {
+		last := time.Unix(grants[n-1].GrantedAt, 0)
+		if t := last.Add(s.cfg.Cooldown); t.After(next) {
+			next = t
+		}
+	}
+
+	recent := withinWindow(grants, now.Add(-s.cfg.Window))
+	if len(recent) >= limit {
+		// The window frees up once the oldest counted grant expires.
+		oldest := time.Unix(recent[len(recent)-limit].GrantedAt, 0)
+		if t := oldest.Add(s.cfg.Window); t.After(next) {
+			next = t
+		}
+	}
+
+	return next, !next.After(now)
+}
+
+// index adds g to the in-memory quota indexes, dropping entries that can no
+// longer affect any quota.
+func (s *faucetService) index(g *model.FaucetGrant) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	horizon := time.Now().Add(-maxDuration(s.cfg.Window, s.cfg.Cooldown))
+	key := strings.ToLower(common.HexToAddress(g.Address).Hex())
+	s.byAddr[key] = append(withinWindow(s.byAddr[key], horizon), g)
+	if g.IP != "" {
+		s.byIP[g.IP] = append(withinWindow(s.byIP[g.IP], horizon), g)
+	}
+}
+
+// withinWindow returns the grants issued after since, preserving order.
+func withinWindow(grants []*model.FaucetGrant, since time.Time) []*model.FaucetGrant {
+	out := make([]*model.FaucetGrant, 0, len(grants))
+	for _, g := range grants {
+		if time.Unix(g.GrantedAt, 0).After(since) {
+			out = append(out, g)
+		}
+	}
+	return out
+}
+
+func maxDuration(a, b time.Duration) time.Duration {
+	if a > b {
+		return a
+	}
+	return b
+}
@@ -0,0 +1,369 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"math/big"
+	"time"
+
+	"github.com/cina_dex_backend/internal/model"
+	"github.com/cina_dex_backend/internal/tracing"
+)
+
+// minAnnualizeSpan is the shortest period whose returns are annualized.
+const minAnnualizeSpan = 24 * time.Hour
+
+// lenderLedger replays a lender's deposits and withdrawals with the average
+// cost method: a withdrawal releases cost in proportion to the FToken burned.
+type lenderLedger struct {
+	shares    *big.Int
+	cost      *big.Int
+	deposited *big.Int
+	withdrawn *big.Int
+	realized  *big.Int
+}
+
+func newLenderLedger() *lenderLedger {
+	return &lenderLedger{
+		shares:    new(big.Int),
+		cost:      new(big.Int),
+		deposited: new(big.Int),
+		withdrawn: new(big.Int),
+		realized:  new(big.Int),
+	}
+}
+
+// apply books a deposit or withdraw event; other types are ignored.
+func (l *lenderLedger) apply(ev *model.ActivityEvent) {
+	amount, err := parseBig(ev.Amount)
+	if err != nil {
+		return
+	}
+	shares, err := parseBig(ev.FTokenAmount)
+	if err != nil {
+		return
+	}
+
+	switch ev.Type {
+	case model.ActivityDeposit:
+		l.deposited.Add(l.deposited, amount)
+		l.cost.Add(l.cost, amount)
+		l.shares.Add(l.shares, shares)
+
+	case model.ActivityWithdraw:
+		l.withdrawn.Add(l.withdrawn, amount)
+		released := new(big.Int)
+		if l.shares.Sign() > 0 {
+			burned := shares
+			if burned.Cmp(l.shares) > 0 {
+				// more burned than the indexed history explains
+				burned = l.shares
+			}
+			released.Mul(l.cost, burned)
+			released.Quo(released, l.shares)
+		}
+		l.realized.Add(l.realized, new(big.Int).Sub(amount, released))
+		l.cost.Sub(l.cost, released)
+		l.shares.Sub(l.shares, shares)
+		if l.shares.Sign() < 0 {
+			l.shares.SetInt64(0)
+		}
+	}
+}
+
+// sharesToUnderlying values FToken at an 18-decimal exchange rate, the same
+// way getLenderPosition derives underlyingBalance.
+func sharesToUnderlying(shares, rate *big.Int) *big.Int {
+	v := new(big.Int).Mul(shares, rate)
+	return v.Quo(v, oneEther)
+}
+
+// lenderFlows returns the indexed deposits and withdrawals of address, oldest first.
+func (s *poolService) lenderFlows(address string) []*model.ActivityEvent {
+	if s.events == nil {
+		return nil
+	}
+	var flows []*model.ActivityEvent
+	for _, ev := range s.events.AccountEvents(address) {
+		if ev.Type == model.ActivityDeposit || ev.Type == model.ActivityWithdraw {
+			flows = append(flows, ev)
+		}
+	}
+	return flows
+}
+
+// fillLenderAccounting sets the cost basis and earnings fields of lp from flows.
+func fillLenderAccounting(lp *model.LenderPosition, flows []*model.ActivityEvent, indexed bool) error {
+	ledger := newLenderLedger()
+	for _, ev := range flows {
+		ledger.apply(ev)
+	}
+
+	balance, err := parseBig(lp.FTokenBalance)
+	if err != nil {
+		return fmt.Errorf("invalid fTokenBalance: %w", err)
+	}
+	underlying, err := parseBig(lp.UnderlyingBalance)
+	if err != nil {
+		return fmt.Errorf("invalid underlyingBalance: %w", err)
+	}
+
+	net := new(big.Int).Sub(ledger.deposited, ledger.withdrawn)
+	lp.NetDeposited = net.String()
+	lp.Interest = new(big.Int).Sub(underlying, net).String()
+	lp.TotalDeposited = ledger.deposited.String()
+	lp.TotalWithdrawn = ledger.withdrawn.String()
+	lp.CostBasis = ledger.cost.String()
+	lp.RealizedEarnings = ledger.realized.String()
+	lp.UnrealizedEarnings = new(big.Int).Sub(underlying, ledger.cost).String()
+	lp.AvgEntryExchangeRate = "0"
+	if ledger.shares.Sign() > 0 {
+		avg := new(big.Int).Mul(ledger.cost, oneEther)
+		lp.AvgEntryExchangeRate = avg.Quo(avg, ledger.shares).String()
+	}
+
+	lp.HistoryComplete = indexed && ledger.shares.Cmp(balance) == 0
+	switch {
+	case !indexed:
+		lp.Warnings = append(lp.Warnings, "event history is not available; accounting fields are empty")
+	case !lp.HistoryComplete:
+		lp.Warnings = append(lp.Warnings, fmt.Sprintf(
+			"indexed deposits/withdrawals explain %s FToken but the balance is %s (FToken transfers or activity before the indexer start block)",
+			ledger.shares, balance))
+	}
+	return nil
+}
+
+// GetLenderReport values the lender's FToken at the start and end of
+// [from, to] using recorded exchange rates and derives period earnings,
+// time-weighted and money-weighted returns. A zero from starts at the first
+// deposit.
+func (s *poolService) GetLenderReport(ctx context.Context, address string, from, to time.Time) (_ *model.LenderReport, err error) {
+	ctx, span := tracing.Start(ctx, "PoolService.GetLenderReport", tracing.Address(address))
+	defer func() { tracing.End(span, err) }()
+
+	if !isHexAddress(address) {
+		return nil, fmt.Errorf("invalid address: %s", address)
+	}
+	lp, err := s.GetLenderPosition(ctx, address)
+	if err != nil {
+		return nil, err
+	}
+	currentRate, err := parseBig(lp.ExchangeRate)
+	if err != nil || currentRate.Sign() <= 0 {
+		return nil, fmt.Errorf("invalid exchangeRate: %s", lp.ExchangeRate)
+	}
+
+	flows := s.lenderFlows(address)
+	now := time.Now()
+	if to.IsZero() || to.After(now) {
+		to = now
+	}
+	if from.IsZero() {
+		from = to.Add(-30 * 24 * time.Hour)
+		if len(flows) > 0 {
+			from = time.Unix(int64(flows[0].BlockTime), 0)
+		}
+	}
+	if !from.Before(to) {
+		return nil, fmt.Errorf("from must be before to")
+	}
+
+	// Split flows at the period boundaries.
+	startLedger, endLedger := newLenderLedger(), newLenderLedger()
+	period := make([]*model.ActivityEvent, 0)
+	for _, ev := range flows {
+		t := time.Unix(int64(ev.BlockTime), 0)
+		if t.After(to) {
+			break
+		}
+		endLedger.apply(ev)
+		if t.After(from) {
+			period = append(period, ev)
+		} else {
+			startLedger.apply(ev)
+		}
+	}
+
+	startRate, ok := s.exchangeRateAt(from, now, currentRate)
+	if !ok {
+		startRate = impliedRate(period)
+		lp.Warnings = append(lp.Warnings, "no exchange-rate history at period start; using the rate of the first flow")
+	}
+	endRate, ok := s.exchangeRateAt(to, now, currentRate)
+	if !ok {
+		endRate = currentRate
+		lp.Warnings = append(lp.Warnings, "no exchange-rate history at period end; using the current rate")
+	}
+
+	deposits := new(big.Int).Sub(endLedger.deposited, startLedger.deposited)
+	withdrawals := new(big.Int).Sub(endLedger.withdrawn, startLedger.withdrawn)
+	ret := &model.LenderPeriodReturn{
+		From:        from.Unix(),
+		To:          to.Unix(),
+		StartFToken: startLedger.shares.String(),
+		EndFToken:   endLedger.shares.String(),
+		Deposits:    deposits.String(),
+		Withdrawals: withdrawals.String(),
+	}
+
+	endValue := sharesToUnderlying(endLedger.shares, endRate)
+	ret.EndExchangeRate = endRate.String()
+	ret.EndValue = endValue.String()
+
+	if startRate != nil && startRate.Sign() > 0 {
+		startValue := sharesToUnderlying(startLedger.shares, startRate)
+		earnings := new(big.Int).Sub(endValue, startValue)
+		earnings.Sub(earnings, deposits)
+		earnings.Add(earnings, withdrawals)
+
+		ret.StartExchangeRate = startRate.String()
+		ret.StartValue = startValue.String()
+		ret.Earnings = earnings.String()
+
+		span := to.Sub(from)
+		growth, _ := new(big.Rat).SetFrac(endRate, startRate).Float64()
+		ret.TwrPercent = fmt.Sprintf("%.4f", (growth-1)*100)
+		if span >= minAnnualizeSpan {
+			ret.TwrAnnualizedPercent = formatPercent(math.Pow(growth, float64(secondsPerYear)/span.Seconds()) - 1)
+		}
+
+		cfs := lenderCashFlows(startValue, endValue, period, from, to)
+		if r, ok := modifiedDietz(earnings, cfs, from, to); ok {
+			ret.MwrPercent = fmt.Sprintf("%.4f", r*100)
+		}
+		if span >= minAnnualizeSpan {
+			if r, ok := xirr(cfs); ok {
+				ret.MwrAnnualizedPercent = formatPercent(r)
+			}
+		}
+	}
+
+	return &model.LenderReport{
+		Position: lp,
+		Period:   ret,
+		Flows:    period,
+	}, nil
+}
+
+// exchangeRateAt returns the recorded exchange rate at t. Times within a
+// minute of now use the current rate.
+func (s *poolService) exchangeRateAt(t, now time.Time, current *big.Int) (*big.Int, bool) {
+	if now.Sub(t) < time.Minute {
+		return current, true
+	}
+	if s.metrics == nil {
+		return nil, false
+	}
+	snap, ok := s.metrics.SnapshotAt(t)
+	if !ok {
+		return nil, false
+	}
+	rate, err := parseBig(snap.State.ExchangeRate)
+	if err != nil || rate.Sign() <= 0 {
+		return nil, false
+	}
+	return rate, true
+}
+
+// impliedRate returns the exchange rate implied by the first flow
+// (amount * 1e18 / fTokenAmount), or nil.
+func impliedRate(flows []*model.ActivityEvent) *big.Int {
+	for _, ev := range flows {
+		amount, err1 := parseBig(ev.Amount)
+		shares, err2 := parseBig(ev.FTokenAmount)
+		if err1 != nil || err2 != nil || shares.Sign() == 0 {
+			continue
+		}
+		r := new(big.Int).Mul(amount, oneEther)
+		return r.Quo(r, shares)
+	}
+	return nil
+}
+
+// cashFlow is an investor-side cash flow: negative when money goes into the pool.
+type cashFlow struct {
+	t      time.Time
+	amount float64
+}
+
+// lenderCashFlows lists the starting value as an investment, each deposit
+// (negative) and withdrawal (positive), and the ending value as a payout.
+func lenderCashFlows(startValue, endValue *big.Int, flows []*model.ActivityEvent, from, to time.Time) []cashFlow {
+	cfs := []cashFlow{{t: from, amount: -bigToFloat(startValue)}}
+	for _, ev := range flows {
+		amount, err := parseBig(ev.Amount)
+		if err != nil {
+			continue
+		}
+		v := bigToFloat(amount)
+		if ev.Type == model.ActivityDeposit {
+			v = -v
+		}
+		cfs = append(cfs, cashFlow{t: time.Unix(int64(ev.BlockTime), 0), amount: v})
+	}
+	return append(cfs, cashFlow{t: to, amount: bigToFloat(endValue)})
+}
+
+// modifiedDietz returns earnings / (start value + time-weighted net inflows).
+func modifiedDietz(earnings *big.Int, cfs []cashFlow, from, to time.Time) (float64, bool) {
+	span := to.Sub(from).Seconds()
+	if span <= 0 {
+		return 0, false
+	}
+	capital := -cfs[0].amount
+	for _, cf := range cfs[1 : len(cfs)-1] {
+		weight := to.Sub(cf.t).Seconds() / span
+		capital += -cf.amount * weight
+	}
+	if capital <= 0 {
+		return 0, false
+	}
+	return bigToFloat(earnings) / capital, true
+}
+
+// xirr solves sum(cf / (1+r)^years) = 0 for the annual rate r by bisection.
+// Flows spanning no time have no rate.
+func xirr(cfs []cashFlow) (float64, bool) {
+	if len(cfs) < 2 || !cfs[len(cfs)-1].t.After(cfs[0].t) {
+		return 0, false
+	}
+	npv := func(r float64) float64 {
+		var sum float64
+		for _, cf := range cfs {
+			years := cf.t.Sub(cfs[0].t).Seconds() / secondsPerYear
+			sum += cf.amount / math.Pow(1+r, years)
+		}
+		return sum
+	}
+
+	lo, hi := -0.9999, 100.0
+	fLo, fHi := npv(lo), npv(hi)
+	if math.IsNaN(fLo) || math.IsNaN(fHi) || fLo*fHi > 0 {
+		return 0, false
+	}
+	for i := 0; i < 200 && hi-lo > 1e-10; i++ {
+		mid := (lo + hi) / 2
+		fMid := npv(mid)
+		if fMid*fLo > 0 {
+			lo, fLo = mid, fMid
+		} else {
+			hi = mid
+		}
+	}
+	return (lo + hi) / 2, true
+}
+
+func bigToFloat(v *big.Int) float64 {
+	f, _ := new(big.Float).SetInt(v).Float64()
+	return f
+}
+
+// formatPercent formats a ratio as a percentage with 2 decimals, "" if not finite.
+func formatPercent(r float64) string {
+	if math.IsInf(r, 0) || math.IsNaN(r) {
+		return ""
+	}
+	return fmt.Sprintf("%.2f", r*100)
+}
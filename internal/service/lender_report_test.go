@@ -0,0 +1,225 @@
+package service
+
+import (
+	"math"
+	"math/big"
+	"testing"
+	"time"
+
+	"github.com/cina_dex_backend/internal/model"
+)
+
+var reportStart = time.Unix(1700000000, 0)
+
+const year = secondsPerYear * time.Second
+
+func flow(typ, amount, fToken string) *model.ActivityEvent {
+	return &model.ActivityEvent{Type: typ, Amount: amount, FTokenAmount: fToken}
+}
+
+func TestLenderLedger(t *testing.T) {
+	tests := []struct {
+		name  string
+		flows []*model.ActivityEvent
+		// shares, cost, deposited, withdrawn, realized
+		want [5]int64
+	}{
+		{
+			name: "no flows",
+			want: [5]int64{0, 0, 0, 0, 0},
+		},
+		{
+			name:  "single deposit",
+			flows: []*model.ActivityEvent{flow(model.ActivityDeposit, "1000", "950")},
+			want:  [5]int64{950, 1000, 1000, 0, 0},
+		},
+		{
+			name: "deposit then full withdraw",
+			flows: []*model.ActivityEvent{
+				flow(model.ActivityDeposit, "1000", "1000"),
+				flow(model.ActivityWithdraw, "1100", "1000"),
+			},
+			want: [5]int64{0, 0, 1000, 1100, 100},
+		},
+		{
+			// average cost 3000 / 2000 shares; burning 500 releases 750.
+			name: "partial withdraw at average cost",
+			flows: []*model.ActivityEvent{
+				flow(model.ActivityDeposit, "1000", "1000"),
+				flow(model.ActivityDeposit, "2000", "1000"),
+				flow(model.ActivityWithdraw, "1500", "500"),
+			},
+			want: [5]int64{1500, 2250, 3000, 1500, 750},
+		},
+		{
+			// burns more than the indexed deposits explain: all cost is released.
+			name: "withdraw beyond indexed shares",
+			flows: []*model.ActivityEvent{
+				flow(model.ActivityDeposit, "1000", "1000"),
+				flow(model.ActivityWithdraw, "2200", "2000"),
+			},
+			want: [5]int64{0, 0, 1000, 2200, 1200},
+		},
+		{
+			name:  "other events ignored",
+			flows: []*model.ActivityEvent{flow(model.ActivityBorrow, "1000", "0")},
+			want:  [5]int64{0, 0, 0, 0, 0},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			l := newLenderLedger()
+			for _, ev := range tt.flows {
+				l.apply(ev)
+			}
+			got := [5]*big.Int{l.shares, l.cost, l.deposited, l.withdrawn, l.realized}
+			for i, name := range []string{"shares", "cost", "deposited", "withdrawn", "realized"} {
+				if got[i].Cmp(big.NewInt(tt.want[i])) != 0 {
+					t.Errorf("%s = %s, want %d", name, got[i], tt.want[i])
+				}
+			}
+		})
+	}
+}
+
+func TestModifiedDietz(t *testing.T) {
+	to := reportStart.Add(100 * time.Hour)
+	at := func(hours int) time.Time { return reportStart.Add(time.Duration(hours) * time.Hour) }
+
+	tests := []struct {
+		name     string
+		earnings int64
+		cfs      []cashFlow
+		// to is the period end, to above when zero.
+		to   time.Time
+		want float64
+		ok   bool
+	}{
+		{
+			// 100 / 1000
+			name:     "no flows",
+			earnings: 100,
+			cfs:      []cashFlow{{reportStart, -1000}, {to, 1100}},
+			want:     0.1,
+			ok:       true,
+		},
+		{
+			// capital = 1000 + 1000 * 0.5 = 1500; 100 / 1500
+			name:     "single deposit mid-period",
+			earnings: 100,
+			cfs:      []cashFlow{{reportStart, -1000}, {at(50), -1000}, {to, 2100}},
+			want:     100.0 / 1500,
+			ok:       true,
+		},
+		{
+			// capital = 1000 * 0.75 - 1050 * 0.25 = 487.5; 50 / 487.5
+			name:     "deposit then full withdraw",
+			earnings: 50,
+			cfs:      []cashFlow{{reportStart, 0}, {at(25), -1000}, {at(75), 1050}, {to, 0}},
+			want:     50 / 487.5,
+			ok:       true,
+		},
+		{
+			name:     "no capital",
+			earnings: 0,
+			cfs:      []cashFlow{{reportStart, 0}, {to, 0}},
+			ok:       false,
+		},
+		{
+			name:     "zero-duration period",
+			earnings: 0,
+			cfs:      []cashFlow{{reportStart, -1000}, {reportStart, -1000}, {reportStart, 2000}},
+			to:       reportStart,
+			ok:       false,
+		},
+		{
+			// deposit at the very end carries no weight.
+			name:     "deposit at period end",
+			earnings: 0,
+			cfs:      []cashFlow{{reportStart, 0}, {to, -1000}, {to, 1000}},
+			ok:       false,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			end := to
+			if !tt.to.IsZero() {
+				end = tt.to
+			}
+			got, ok := modifiedDietz(big.NewInt(tt.earnings), tt.cfs, reportStart, end)
+			if ok != tt.ok {
+				t.Fatalf("ok = %v, want %v", ok, tt.ok)
+			}
+			if ok && math.Abs(got-tt.want) > 1e-9 {
+				t.Errorf("modifiedDietz = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestXIRR(t *testing.T) {
+	at := func(years float64) time.Time { return reportStart.Add(time.Duration(years * float64(year))) }
+
+	tests := []struct {
+		name string
+		cfs  []cashFlow
+		want float64
+		ok   bool
+	}{
+		{
+			// 1000 -> 1100 in a year
+			name: "no flows",
+			cfs:  []cashFlow{{at(0), -1000}, {at(1), 1100}},
+			want: 0.10,
+			ok:   true,
+		},
+		{
+			// 1000 -> 1210 in two years: 1.1^2
+			name: "single deposit",
+			cfs:  []cashFlow{{at(0), 0}, {at(0), -1000}, {at(2), 1210}},
+			want: 0.10,
+			ok:   true,
+		},
+		{
+			// 1000 -> 1050 in half a year: 1.05^2 - 1
+			name: "deposit then full withdraw",
+			cfs:  []cashFlow{{at(0), 0}, {at(0), -1000}, {at(0.5), 1050}, {at(1), 0}},
+			want: 0.1025,
+			ok:   true,
+		},
+		{
+			// 1000 -> 500 in a year
+			name: "loss",
+			cfs:  []cashFlow{{at(0), -1000}, {at(1), 500}},
+			want: -0.5,
+			ok:   true,
+		},
+		{
+			// everything is lost: npv is -1000 at every rate.
+			name: "no root",
+			cfs:  []cashFlow{{at(0), -1000}, {at(1), 0}},
+			ok:   false,
+		},
+		{
+			name: "zero-duration period",
+			cfs:  []cashFlow{{at(0), -1000}, {at(0), 1000}},
+			ok:   false,
+		},
+		{
+			name: "only payouts",
+			cfs:  []cashFlow{{at(0), 0}, {at(0.5), 100}, {at(1), 100}},
+			ok:   false,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, ok := xirr(tt.cfs)
+			if ok != tt.ok {
+				t.Fatalf("ok = %v, want %v", ok, tt.ok)
+			}
+			if ok && math.Abs(got-tt.want) > 1e-6 {
+				t.Errorf("xirr = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,126 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/cina_dex_backend/internal/model"
+	"github.com/cina_dex_backend/internal/onchain"
+	"github.com/cina_dex_backend/internal/tracing"
+)
+
+const (
+	// loanScanWorkers bounds concurrent loans(uint256) calls during a scan.
+	loanScanWorkers = 8
+	// loanScanMaxAge is how long a scan result is reused before rescanning.
+	loanScanMaxAge = time.Minute
+)
+
+// LoanScanner enumerates every loan of the LendingPool by iterating
+// 0 .. nextLoanId-1. Closed (inactive) loans never change again, so they are
+// read once and kept; only active and new loans are re-read on each scan.
+type LoanScanner struct {
+	client onchain.Client
+
+	mu       sync.Mutex
+	closed   map[uint64]*model.Loan
+	last     []*model.Loan
+	lastScan time.Time
+}
+
+// NewLoanScanner constructs a LoanScanner backed by the on-chain client.
+func NewLoanScanner(c onchain.Client) *LoanScanner {
+	return &LoanScanner{
+		client: c,
+		closed: make(map[uint64]*model.Loan),
+	}
+}
+
+// Loans returns all loans ordered by ID. Results younger than
+// loanScanMaxAge are served from memory.
+func (s *LoanScanner) Loans(ctx context.Context) (_ []*model.Loan, err error) {
+	ctx, span := tracing.Start(ctx, "LoanScanner.Loans")
+	defer func() { tracing.End(span, err) }()
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.last != nil && time.Since(s.lastScan) < loanScanMaxAge {
+		return s.last, nil
+	}
+
+	next, err := s.client.NextLoanID(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	var pending []uint64
+	loans := make([]*model.Loan, 0, next)
+	for id := uint64(0); id < next; id++ {
+		if loan, ok := s.closed[id]; ok {
+			loans = append(loans, loan)
+		} else {
+			pending = append(pending, id)
+		}
+	}
+
+	fetched, err := s.fetch(ctx, pending)
+	if err != nil {
+		return nil, err
+	}
+	for _, loan := range fetched {
+		if !loan.IsActive {
+			s.closed[loan.ID] = loan
+		}
+		loans = append(loans, loan)
+	}
+	sort.Slice(loans, func(i, j int) bool { return loans[i].ID < loans[j].ID })
+
+	s.last = loans
+	s.lastScan = time.Now()
+	return loans, nil
+}
+
+// ActiveLoans returns the loans that are still open.
+func (s *LoanScanner) ActiveLoans(ctx context.Context) ([]*model.Loan, error) {
+	loans, err := s.Loans(ctx)
+	if err != nil {
+		return nil, err
+	}
+	active := make([]*model.Loan, 0, len(loans))
+	for _, loan := range loans {
+		if loan.IsActive {
+			active = append(active, loan)
+		}
+	}
+	return active, nil
+}
+
+// fetch reads the given loans with at most loanScanWorkers calls in flight.
+func (s *LoanScanner) fetch(ctx context.Context, ids []uint64) ([]*model.Loan, error) {
+	loans := make([]*model.Loan, len(ids))
+	errs := make([]error, len(ids))
+	sem := make(chan struct{}, loanScanWorkers)
+
+	var wg sync.WaitGroup
+	for i, id := range ids {
+		wg.Add(1)
+		sem <- struct{}{}
+		go func(i int, id uint64) {
+			defer wg.Done()
+			defer func() { <-sem }()
+			loans[i], errs[i] = s.client.GetLoan(ctx, id)
+		}(i, id)
+	}
+	wg.Wait()
+
+	for i, err := range errs {
+		if err != nil {
+			return nil, fmt.Errorf("get loan %d: %w", ids[i], err)
+		}
+	}
+	return loans, nil
+}
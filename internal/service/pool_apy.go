@@ -0,0 +1,163 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"math/big"
+	"time"
+
+	"github.com/cina_dex_backend/internal/model"
+	"github.com/cina_dex_backend/internal/tracing"
+)
+
+// apyWindows are the trailing windows reported by GetPoolAPY.
+var apyWindows = []struct {
+	name string
+	span time.Duration
+}{
+	{"1d", 24 * time.Hour},
+	{"7d", 7 * 24 * time.Hour},
+	{"30d", 30 * 24 * time.Hour},
+}
+
+// minAPYSpan is the shortest history a trailing yield is annualized from;
+// shorter spans amplify rounding and single-loan noise too much.
+const minAPYSpan = time.Hour
+
+// GetPoolAPY compares the current exchange rate with recorded snapshots for
+// the trailing windows and estimates the forward yield from active loans.
+func (s *poolService) GetPoolAPY(ctx context.Context) (_ *model.PoolAPY, err error) {
+	ctx, span := tracing.Start(ctx, "PoolService.GetPoolAPY")
+	defer func() { tracing.End(span, err) }()
+
+	ps, err := s.GetPoolState(ctx)
+	if err != nil {
+		return nil, err
+	}
+	rate, err := parseBig(ps.ExchangeRate)
+	if err != nil || rate.Sign() <= 0 {
+		return nil, fmt.Errorf("invalid exchangeRate: %s", ps.ExchangeRate)
+	}
+
+	now := time.Now()
+	out := &model.PoolAPY{
+		ExchangeRate: rate.String(),
+		AsOf:         now.Unix(),
+		Trailing:     make([]*model.TrailingAPY, 0, len(apyWindows)),
+	}
+
+	for _, w := range apyWindows {
+		t := s.trailingAPY(w.name, w.span, rate, now)
+		if !t.Available {
+			out.Warnings = append(out.Warnings, fmt.Sprintf("%s: not enough exchange-rate history yet", w.name))
+		}
+		out.Trailing = append(out.Trailing, t)
+	}
+
+	forward, err := s.forwardAPY(ctx, ps)
+	if err != nil {
+		out.Warnings = append(out.Warnings, fmt.Sprintf("forward estimate unavailable: %v", err))
+	} else {
+		out.Forward = forward
+	}
+
+	return out, nil
+}
+
+// trailingAPY uses the snapshot at now-span, or the oldest snapshot when
+// history does not reach back that far (flagged Partial).
+func (s *poolService) trailingAPY(name string, span time.Duration, rate *big.Int, now time.Time) *model.TrailingAPY {
+	t := &model.TrailingAPY{Window: name}
+	if s.metrics == nil {
+		return t
+	}
+
+	snap, ok := s.metrics.SnapshotAt(now.Add(-span))
+	if !ok {
+		if snap, ok = s.metrics.FirstSnapshot(); !ok {
+			return t
+		}
+		t.Partial = true
+	}
+
+	elapsed := now.Sub(time.Unix(int64(snap.BlockTime), 0))
+	past, err := parseBig(snap.State.ExchangeRate)
+	if err != nil || past.Sign() <= 0 || elapsed < minAPYSpan {
+		return t
+	}
+
+	t.Available = true
+	t.SpanSeconds = int64(elapsed / time.Second)
+	t.FromTime = int64(snap.BlockTime)
+	t.FromBlock = snap.BlockNumber
+	t.FromExchangeRate = past.String()
+
+	growth, _ := new(big.Rat).SetFrac(rate, past).Float64()
+	periods := float64(secondsPerYear) / elapsed.Seconds()
+	t.AprPercent = fmt.Sprintf("%.2f", (growth-1)*periods*100)
+	if apy := math.Pow(growth, periods) - 1; !math.IsInf(apy, 0) && !math.IsNaN(apy) {
+		t.ApyPercent = fmt.Sprintf("%.2f", apy*100)
+	}
+	return t
+}
+
+// forwardAPY annualizes the fixed interest of every active loan
+// ((repayment - principal) * year / duration) and spreads it over all
+// supplied assets.
+func (s *poolService) forwardAPY(ctx context.Context, ps *model.PoolState) (*model.ForwardAPY, error) {
+	if s.loans == nil {
+		return nil, fmt.Errorf("loan scanner not configured")
+	}
+	loans, err := s.loans.ActiveLoans(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	assets, err := parseBig(ps.TotalAssets)
+	if err != nil {
+		return nil, fmt.Errorf("invalid totalAssets: %w", err)
+	}
+
+	annual := new(big.Int)
+	principalSum := new(big.Int)
+	for _, loan := range loans {
+		if loan.Duration == 0 {
+			continue
+		}
+		principal, err := parseBig(loan.Principal)
+		if err != nil {
+			continue
+		}
+		repayment, err := parseBig(loan.RepaymentAmount)
+		if err != nil || repayment.Cmp(principal) < 0 {
+			continue
+		}
+		interest := new(big.Int).Sub(repayment, principal)
+		interest.Mul(interest, yearInSec)
+		interest.Quo(interest, new(big.Int).SetUint64(loan.Duration))
+		annual.Add(annual, interest)
+		principalSum.Add(principalSum, principal)
+	}
+
+	util := utilization(ps)
+	return &model.ForwardAPY{
+		ActiveLoans:        len(loans),
+		TotalAssets:        assets.String(),
+		TotalBorrowed:      ps.TotalBorrowed,
+		Utilization:        util.String(),
+		UtilizationPercent: formatRatioPercent(util),
+		AnnualInterest:     annual.String(),
+		BorrowAprPercent:   percentOf(annual, principalSum),
+		SupplyAprPercent:   percentOf(annual, assets),
+	}, nil
+}
+
+// percentOf formats num / den as a percentage with 2 decimals, "0.00" for den = 0.
+func percentOf(num, den *big.Int) string {
+	if den.Sign() == 0 {
+		return "0.00"
+	}
+	r := new(big.Rat).SetFrac(new(big.Int).Mul(num, hundred), den)
+	return r.FloatString(2)
+}
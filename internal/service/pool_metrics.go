@@ -0,0 +1,217 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"math/big"
+	"time"
+
+	"github.com/cina_dex_backend/internal/config"
+	"github.com/cina_dex_backend/internal/health"
+	"github.com/cina_dex_backend/internal/lifecycle"
+	"github.com/cina_dex_backend/internal/model"
+	"github.com/cina_dex_backend/internal/onchain"
+	"github.com/cina_dex_backend/internal/store"
+)
+
+// Pool metrics served as time series.
+const (
+	PoolMetricTVL                = "tvl"
+	PoolMetricBorrowed           = "borrowed"
+	PoolMetricAvailableLiquidity = "available-liquidity"
+	PoolMetricExchangeRate       = "exchange-rate"
+	PoolMetricUtilization        = "utilization"
+)
+
+// maxMetricPoints bounds how many points one metric query may return.
+const maxMetricPoints = 1000
+
+// ErrInvalidMetricQuery is returned by Series for an unknown metric, an
+// interval under a minute or a bad range.
+var ErrInvalidMetricQuery = errors.New("invalid pool metric query")
+
+// PoolMetricsService keeps periodic pool state snapshots and serves them as
+// per-metric time series.
+type PoolMetricsService interface {
+	// Snapshot reads the pool state at the head block and records it.
+	Snapshot(ctx context.Context) (*model.PoolSnapshot, error)
+	// Series returns metric over [from, to), keeping the last snapshot per interval bucket.
+	Series(ctx context.Context, metric string, from, to time.Time, interval time.Duration) (*model.PoolMetricSeries, error)
+	// SnapshotAt returns the latest snapshot taken at or before t.
+	SnapshotAt(t time.Time) (*model.PoolSnapshot, bool)
+	// FirstSnapshot returns the oldest retained snapshot.
+	FirstSnapshot() (*model.PoolSnapshot, bool)
+}
+
+type poolMetricsService struct {
+	client    onchain.Client
+	snapshots *store.Series[model.PoolSnapshot]
+}
+
+// NewPoolMetricsService opens the persisted pool snapshots under cfg.DataDir.
+func NewPoolMetricsService(cfg *config.Config, c onchain.Client) (PoolMetricsService, error) {
+	snapLog, err := store.OpenAppendLog(cfg.DataPath("pool_snapshots.jsonl"))
+	if err != nil {
+		return nil, err
+	}
+	snapshots, err := store.NewSeries[model.PoolSnapshot](snapLog, cfg.PoolHistoryRetention)
+	if err != nil {
+		return nil, fmt.Errorf("load pool snapshots: %w", err)
+	}
+	return &poolMetricsService{client: c, snapshots: snapshots}, nil
+}
+
+// Snapshot pins the read to the head block so the recorded block number and
+// time match the state exactly.
+func (s *poolMetricsService) Snapshot(ctx context.Context) (*model.PoolSnapshot, error) {
+	head, err := s.client.HeadBlock(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if last, ok := s.snapshots.Last(); ok && last.Value.BlockNumber >= head.Number {
+		// no new block since the previous snapshot
+		return &last.Value, nil
+	}
+
+	ps, err := s.client.GetPoolStateAt(ctx, head.Number)
+	if err != nil {
+		return nil, err
+	}
+
+	snap := &model.PoolSnapshot{
+		BlockNumber: head.Number,
+		BlockTime:   head.Time,
+		State:       *ps,
+		Utilization: utilization(ps).String(),
+	}
+	if err := s.snapshots.Add(time.Unix(int64(head.Time), 0), *snap); err != nil {
+		return nil, fmt.Errorf("record pool snapshot: %w", err)
+	}
+	return snap, nil
+}
+
+func (s *poolMetricsService) Series(ctx context.Context, metric string, from, to time.Time, interval time.Duration) (*model.PoolMetricSeries, error) {
+	if _, err := poolMetricValue(metric, &model.PoolSnapshot{}); err != nil {
+		return nil, err
+	}
+	if !from.Before(to) {
+		return nil, fmt.Errorf("%w: from must be before to", ErrInvalidMetricQuery)
+	}
+	if interval < time.Minute {
+		return nil, fmt.Errorf("%w: interval must be at least 1m", ErrInvalidMetricQuery)
+	}
+	if to.Sub(from)/interval > maxMetricPoints {
+		return nil, fmt.Errorf("%w: range too large, more than %d points", ErrInvalidMetricQuery, maxMetricPoints)
+	}
+
+	step := int64(interval / time.Second)
+	points := make([]*model.MetricPoint, 0)
+	var lastBucket int64 = -1
+	for _, p := range s.snapshots.Range(from, to) {
+		value, _ := poolMetricValue(metric, &p.Value)
+		point := &model.MetricPoint{
+			Time:        p.Time,
+			BlockNumber: p.Value.BlockNumber,
+			Value:       value,
+		}
+		// keep the last snapshot of each bucket
+		if bucket := p.Time - p.Time%step; bucket == lastBucket {
+			points[len(points)-1] = point
+		} else {
+			points = append(points, point)
+			lastBucket = bucket
+		}
+	}
+
+	return &model.PoolMetricSeries{
+		Metric:   metric,
+		Interval: interval.String(),
+		From:     from.Unix(),
+		To:       to.Unix(),
+		Points:   points,
+	}, nil
+}
+
+func (s *poolMetricsService) SnapshotAt(t time.Time) (*model.PoolSnapshot, bool) {
+	p, ok := s.snapshots.At(t)
+	if !ok {
+		return nil, false
+	}
+	return &p.Value, true
+}
+
+func (s *poolMetricsService) FirstSnapshot() (*model.PoolSnapshot, bool) {
+	p, ok := s.snapshots.First()
+	if !ok {
+		return nil, false
+	}
+	return &p.Value, true
+}
+
+// poolMetricValue extracts a named metric from a snapshot.
+func poolMetricValue(metric string, snap *model.PoolSnapshot) (string, error) {
+	switch metric {
+	case PoolMetricTVL:
+		return snap.State.TotalAssets, nil
+	case PoolMetricBorrowed:
+		return snap.State.TotalBorrowed, nil
+	case PoolMetricAvailableLiquidity:
+		return snap.State.AvailableLiquidity, nil
+	case PoolMetricExchangeRate:
+		return snap.State.ExchangeRate, nil
+	case PoolMetricUtilization:
+		return snap.Utilization, nil
+	default:
+		return "", fmt.Errorf("%w: unknown pool metric %q", ErrInvalidMetricQuery, metric)
+	}
+}
+
+// utilization returns TotalBorrowed / TotalAssets with 18 decimals, 0 for an empty pool.
+func utilization(ps *model.PoolState) *big.Int {
+	assets, err := parseBig(ps.TotalAssets)
+	if err != nil || assets.Sign() == 0 {
+		return new(big.Int)
+	}
+	borrowed, err := parseBig(ps.TotalBorrowed)
+	if err != nil {
+		return new(big.Int)
+	}
+	u := new(big.Int).Mul(borrowed, oneEther)
+	return u.Quo(u, assets)
+}
+
+// StartPoolSnapshotter launches a background goroutine that records a pool
+// state snapshot every interval.
+func StartPoolSnapshotter(ctx context.Context, workers *lifecycle.Workers, svc PoolMetricsService, interval time.Duration) {
+	if svc == nil || interval <= 0 {
+		return
+	}
+	health.Register("pool_snapshotter", 3*interval, false)
+
+	workers.Go("pool snapshotter", func() {
+		snapshotOnce(ctx, svc)
+
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-ctx.Done():
+				slog.Info("pool snapshotter stopped: context cancelled")
+				return
+			case <-ticker.C:
+				snapshotOnce(ctx, svc)
+			}
+		}
+	})
+}
+
+func snapshotOnce(ctx context.Context, svc PoolMetricsService) {
+	_, err := svc.Snapshot(ctx)
+	jobDone("pool_snapshotter", err)
+	if err != nil {
+		slog.ErrorContext(ctx, "pool snapshotter: snapshot", "err", err)
+	}
+}
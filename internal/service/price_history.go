@@ -0,0 +1,199 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"math/big"
+	"time"
+
+	"github.com/cina_dex_backend/internal/config"
+	"github.com/cina_dex_backend/internal/lifecycle"
+	"github.com/cina_dex_backend/internal/model"
+	"github.com/cina_dex_backend/internal/store"
+)
+
+// PriceSourceMedian names the recorded aggregated (median) price.
+const PriceSourceMedian = "median"
+
+// maxCandles bounds how many OHLC buckets one query may return.
+const maxCandles = 1000
+
+// ErrInvalidOHLC is returned by OHLC for an unknown source, an unsupported
+// interval or a bad range.
+var ErrInvalidOHLC = errors.New("invalid OHLC query")
+
+// candleIntervals lists the OHLC intervals accepted by the API.
+var candleIntervals = map[string]time.Duration{
+	"1m":  time.Minute,
+	"5m":  5 * time.Minute,
+	"15m": 15 * time.Minute,
+	"30m": 30 * time.Minute,
+	"1h":  time.Hour,
+	"4h":  4 * time.Hour,
+	"1d":  24 * time.Hour,
+}
+
+// PriceHistoryService stores sampled BNB/USD prices and serves OHLC candles.
+type PriceHistoryService interface {
+	// Record stores the prices of a validated price check.
+	Record(info *model.PriceInfo)
+	// OHLC buckets the prices of source in [from, to) by interval ("1m" .. "1d").
+	OHLC(ctx context.Context, source, interval string, from, to time.Time) (*model.PriceCandles, error)
+	// PriceAt returns the latest recorded price of source at or before t.
+	PriceAt(source string, t time.Time) (*big.Int, bool)
+}
+
+// priceHistory keeps one series per source: the aggregated median, the
+// oracle wrapper (the price the contract uses) and the raw feed rounds.
+type priceHistory struct {
+	series map[string]*store.Series[model.PricePoint]
+}
+
+// NewPriceHistoryService opens the persisted price series under cfg.DataDir.
+func NewPriceHistoryService(cfg *config.Config) (PriceHistoryService, error) {
+	h := &priceHistory{series: make(map[string]*store.Series[model.PricePoint])}
+	for _, src := range []string{PriceSourceMedian, PriceSourceOracle, PriceSourceChainlink} {
+		plog, err := store.OpenAppendLog(cfg.DataPath("price_history_" + src + ".jsonl"))
+		if err != nil {
+			return nil, err
+		}
+		series, err := store.NewSeries[model.PricePoint](plog, cfg.Price.HistoryRetention)
+		if err != nil {
+			return nil, fmt.Errorf("load %s price history: %w", src, err)
+		}
+		h.series[src] = series
+	}
+	return h, nil
+}
+
+// Record stores the median and oracle prices at the check time, and the raw
+// feed answer at its round's updatedAt (once per round).
+func (h *priceHistory) Record(info *model.PriceInfo) {
+	checkedAt := time.Unix(info.CheckedAt, 0)
+
+	h.add(PriceSourceMedian, checkedAt, model.PricePoint{Price: info.MedianPrice})
+	if info.OraclePrice != "" {
+		h.add(PriceSourceOracle, checkedAt, model.PricePoint{Price: info.OraclePrice})
+	}
+
+	if info.RoundID != "" && info.FeedPrice != "" && info.UpdatedAt != 0 {
+		feed := h.series[PriceSourceChainlink]
+		if last, ok := feed.Last(); !ok || last.Value.RoundID != info.RoundID {
+			h.add(PriceSourceChainlink, time.Unix(int64(info.UpdatedAt), 0), model.PricePoint{
+				Price:   info.FeedPrice,
+				RoundID: info.RoundID,
+			})
+		}
+	}
+}
+
+func (h *priceHistory) add(source string, t time.Time, p model.PricePoint) {
+	if p.Price == "" {
+		return
+	}
+	if err := h.series[source].Add(t, p); err != nil {
+		slog.Error("price history: record", "source", source, "err", err)
+	}
+}
+
+// OHLC builds candles aligned to multiples of interval since the unix epoch.
+// Buckets without samples are omitted.
+func (h *priceHistory) OHLC(ctx context.Context, source, interval string, from, to time.Time) (*model.PriceCandles, error) {
+	series, ok := h.series[source]
+	if !ok {
+		return nil, fmt.Errorf("%w: unknown price source %q", ErrInvalidOHLC, source)
+	}
+	step, ok := candleIntervals[interval]
+	if !ok {
+		return nil, fmt.Errorf("%w: unsupported interval %q", ErrInvalidOHLC, interval)
+	}
+	if !from.Before(to) {
+		return nil, fmt.Errorf("%w: from must be before to", ErrInvalidOHLC)
+	}
+	if to.Sub(from)/step > maxCandles {
+		return nil, fmt.Errorf("%w: range too large, more than %d %s candles", ErrInvalidOHLC, maxCandles, interval)
+	}
+
+	stepSec := int64(step / time.Second)
+	candles := make([]*model.Candle, 0)
+	var (
+		cur       *model.Candle
+		high, low *big.Int
+	)
+	for _, p := range series.Range(from, to) {
+		price, err := parseBig(p.Value.Price)
+		if err != nil {
+			continue
+		}
+		bucket := p.Time - p.Time%stepSec
+		if cur == nil || cur.Time != bucket {
+			cur = &model.Candle{Time: bucket, Open: price.String()}
+			candles = append(candles, cur)
+			high, low = price, price
+		}
+		if price.Cmp(high) > 0 {
+			high = price
+		}
+		if price.Cmp(low) < 0 {
+			low = price
+		}
+		cur.High = high.String()
+		cur.Low = low.String()
+		cur.Close = price.String()
+		cur.Samples++
+	}
+
+	return &model.PriceCandles{
+		Source:   source,
+		Interval: interval,
+		From:     from.Unix(),
+		To:       to.Unix(),
+		Candles:  candles,
+	}, nil
+}
+
+func (h *priceHistory) PriceAt(source string, t time.Time) (*big.Int, bool) {
+	series, ok := h.series[source]
+	if !ok {
+		return nil, false
+	}
+	p, ok := series.At(t)
+	if !ok {
+		return nil, false
+	}
+	price, err := parseBig(p.Value.Price)
+	if err != nil {
+		return nil, false
+	}
+	return price, true
+}
+
+// StartPriceRecorder records every new validated price written to the cache
+// by the state updater into history.
+func StartPriceRecorder(ctx context.Context, workers *lifecycle.Workers, cache *StateCache, history PriceHistoryService) {
+	if cache == nil || history == nil {
+		return
+	}
+	updates, unsubscribe := cache.Subscribe()
+
+	workers.Go("price recorder", func() {
+		defer unsubscribe()
+
+		var lastChecked int64
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-updates:
+				info, ok := cache.GetPriceInfo()
+				if !ok || info.CheckedAt == lastChecked {
+					continue
+				}
+				lastChecked = info.CheckedAt
+				history.Record(info)
+			}
+		}
+	})
+}
@@ -0,0 +1,215 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"math/big"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/cina_dex_backend/internal/config"
+	"github.com/cina_dex_backend/internal/model"
+)
+
+// PriceService returns the BNB/USD price aggregated over several price
+// sources, together with staleness and deviation checks.
+type PriceService interface {
+	GetNativePrice(ctx context.Context) (*model.PriceInfo, error)
+}
+
+// priceService is the default implementation of PriceService.
+type priceService struct {
+	sources         []PriceSource
+	maxDeviationBps int64
+	minSources      int
+}
+
+// NewPriceService constructs a PriceService that combines the given sources.
+func NewPriceService(cfg *config.Config, sources []PriceSource) PriceService {
+	minSources := cfg.Price.MinSources
+	if minSources <= 0 {
+		minSources = 1
+	}
+	return &priceService{
+		sources:         sources,
+		maxDeviationBps: cfg.Price.MaxDeviationBps,
+		minSources:      minSources,
+	}
+}
+
+// GetNativePrice fetches every source concurrently and combines them:
+//   - samples that failed or are stale (e.g. a Chainlink round older than the
+//     heartbeat, answeredInRound < roundId) are excluded;
+//   - the median of the remaining samples is the reference the sources are
+//     checked against; if any valid sample deviates from it by more than
+//     maxDeviationBps, the result is flagged Deviating;
+//   - the price is the oracle wrapper's, since that is what the contract
+//     checks LTV against; without it the median is used and flagged Unsafe;
+//   - the result is Unsafe when Stale, Deviating or backed by fewer than
+//     minSources valid samples.
+//
+// Failed checks are reported through flags rather than errors so callers can
+// decide whether to serve a flagged price; an error is only returned when no
+// source produced a usable price.
+func (s *priceService) GetNativePrice(ctx context.Context) (*model.PriceInfo, error) {
+	samples := s.fetchAll(ctx)
+
+	info := &model.PriceInfo{
+		Sources:   samples,
+		CheckedAt: time.Now().Unix(),
+	}
+
+	var (
+		valid  []*big.Int
+		oracle *big.Int
+	)
+	validSamples := make([]*model.PriceSample, 0, len(samples))
+	for _, sample := range samples {
+		switch sample.Source {
+		case PriceSourceOracle:
+			info.OraclePrice = sample.Price
+		case PriceSourceChainlink:
+			info.FeedPrice = sample.Price
+			info.RoundID = sample.RoundID
+			info.UpdatedAt = sample.UpdatedAt
+			if sample.Stale {
+				info.Stale = true
+			}
+		}
+		if sample.Stale {
+			for _, w := range sample.Warnings {
+				info.Warnings = append(info.Warnings, sample.Source+": "+w)
+			}
+			continue
+		}
+		p, err := parseBig(sample.Price)
+		if err != nil || p.Sign() <= 0 {
+			continue
+		}
+		valid = append(valid, p)
+		validSamples = append(validSamples, sample)
+		if sample.Source == PriceSourceOracle {
+			oracle = p
+		}
+	}
+
+	if len(valid) == 0 {
+		return nil, fmt.Errorf("no valid price source: %v", info.Warnings)
+	}
+
+	median := medianBig(valid)
+	info.MedianPrice = median.String()
+	info.Price = info.MedianPrice
+	if oracle != nil {
+		info.Price = oracle.String()
+	} else {
+		info.Warnings = append(info.Warnings, "oracle price unavailable; contract checks LTV against it")
+		info.Unsafe = true
+	}
+
+	// measure against the served price: the oracle, or the median without it.
+	ref, refName := median, "median"
+	if oracle != nil {
+		ref, refName = oracle, PriceSourceOracle
+	}
+	maxDev := new(big.Int)
+	for i, p := range valid {
+		dev := deviationBps(p, ref)
+		validSamples[i].DeviationBps = dev.String()
+		if dev.Cmp(maxDev) > 0 {
+			maxDev = dev
+		}
+	}
+	info.DeviationBps = maxDev.String()
+	if maxDev.Cmp(big.NewInt(s.maxDeviationBps)) > 0 {
+		info.Deviating = true
+		info.Warnings = append(info.Warnings, fmt.Sprintf("sources deviate up to %s bps from %s (max %d)", maxDev, refName, s.maxDeviationBps))
+	}
+
+	if len(valid) < s.minSources {
+		info.Warnings = append(info.Warnings, fmt.Sprintf("only %d valid price sources (min %d)", len(valid), s.minSources))
+		info.Unsafe = true
+	}
+	if info.Stale || info.Deviating {
+		info.Unsafe = true
+	}
+
+	return info, nil
+}
+
+// fetchAll queries all sources in parallel. Failed sources are returned as
+// stale samples carrying the error as a warning.
+func (s *priceService) fetchAll(ctx context.Context) []*model.PriceSample {
+	samples := make([]*model.PriceSample, len(s.sources))
+
+	var wg sync.WaitGroup
+	for i, src := range s.sources {
+		wg.Add(1)
+		go func(i int, src PriceSource) {
+			defer wg.Done()
+			sample, err := src.Fetch(ctx)
+			if err != nil {
+				sample = &model.PriceSample{
+					Source:   src.Name(),
+					Stale:    true,
+					Warnings: []string{fmt.Sprintf("unavailable: %v", err)},
+				}
+			}
+			samples[i] = sample
+		}(i, src)
+	}
+	wg.Wait()
+
+	return samples
+}
+
+// validateRound returns the reasons a feed round should not be trusted.
+func validateRound(round *model.PriceRound, heartbeat time.Duration, now time.Time) []string {
+	var warnings []string
+
+	answer, err := parseBig(round.Answer)
+	if err != nil || answer.Sign() <= 0 {
+		warnings = append(warnings, "feed answer is not positive")
+	}
+	if round.UpdatedAt == 0 {
+		warnings = append(warnings, "feed round is incomplete (updatedAt = 0)")
+	}
+
+	roundID, errR := parseBig(round.RoundID)
+	answeredIn, errA := parseBig(round.AnsweredInRound)
+	if errR == nil && errA == nil && answeredIn.Cmp(roundID) < 0 {
+		warnings = append(warnings, fmt.Sprintf("answeredInRound %s < roundId %s", answeredIn, roundID))
+	}
+
+	if round.UpdatedAt != 0 && heartbeat > 0 {
+		age := now.Sub(time.Unix(int64(round.UpdatedAt), 0))
+		if age > heartbeat {
+			warnings = append(warnings, fmt.Sprintf("feed updated %s ago (heartbeat %s)", age.Truncate(time.Second), heartbeat))
+		}
+	}
+
+	return warnings
+}
+
+// medianBig returns the median of vs; for an even count it is the mean of
+// the two middle values.
+func medianBig(vs []*big.Int) *big.Int {
+	sorted := append([]*big.Int(nil), vs...)
+	sort.Slice(sorted, func(i, j int) bool { return sorted[i].Cmp(sorted[j]) < 0 })
+
+	mid := len(sorted) / 2
+	if len(sorted)%2 == 1 {
+		return new(big.Int).Set(sorted[mid])
+	}
+	sum := new(big.Int).Add(sorted[mid-1], sorted[mid])
+	return sum.Quo(sum, big.NewInt(2))
+}
+
+// deviationBps returns |a - b| * 10000 / b.
+func deviationBps(a, b *big.Int) *big.Int {
+	d := new(big.Int).Sub(a, b)
+	d.Abs(d)
+	d.Mul(d, bpsDenom)
+	return d.Quo(d, b)
+}
@@ -0,0 +1,155 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"math/big"
+	"time"
+
+	"github.com/cina_dex_backend/internal/config"
+	"github.com/cina_dex_backend/internal/model"
+	"github.com/cina_dex_backend/internal/onchain"
+)
+
+// PriceSource is a single provider of the BNB/USD price. Sources report
+// problems through PriceSample.Stale / Warnings; an error means no price at all.
+type PriceSource interface {
+	Name() string
+	Fetch(ctx context.Context) (*model.PriceSample, error)
+}
+
+// Names of the built-in price sources.
+const (
+	PriceSourceOracle    = "oracle"
+	PriceSourceChainlink = "chainlink"
+	PriceSourceDexTWAP   = "dex-twap"
+)
+
+// NewPriceSources builds the sources enabled by cfg: the oracle wrapper is
+// always present, the raw Chainlink feed and the DEX TWAP only when configured.
+func NewPriceSources(cfg *config.Config, c onchain.Client) []PriceSource {
+	sources := []PriceSource{&oracleSource{client: c}}
+
+	if isHexAddress(cfg.ChainConfig.PriceFeed) {
+		sources = append(sources, &chainlinkSource{
+			client:    c,
+			heartbeat: cfg.Price.FeedHeartbeat,
+		})
+	}
+
+	if isHexAddress(cfg.Price.DexPool) {
+		sources = append(sources, &dexTWAPSource{
+			client:        c,
+			pool:          cfg.Price.DexPool,
+			window:        cfg.Price.DexTWAPWindow,
+			baseIsToken0:  cfg.Price.DexBaseIsToken0,
+			baseDecimals:  cfg.Price.DexBaseDecimals,
+			quoteDecimals: cfg.Price.DexQuoteDecimals,
+		})
+	}
+
+	return sources
+}
+
+// oracleSource reads ChainlinkOracle.getPrice(address(0)), the price the
+// LendingPool contract itself uses.
+type oracleSource struct {
+	client onchain.Client
+}
+
+func (s *oracleSource) Name() string { return PriceSourceOracle }
+
+func (s *oracleSource) Fetch(ctx context.Context) (*model.PriceSample, error) {
+	price, err := s.client.GetNativePrice(ctx)
+	if err != nil {
+		return nil, err
+	}
+	sample := &model.PriceSample{Source: s.Name(), Price: price.String()}
+	if price.Sign() <= 0 {
+		sample.Stale = true
+		sample.Warnings = append(sample.Warnings, "oracle returned non-positive price")
+	}
+	return sample, nil
+}
+
+// chainlinkSource reads latestRoundData() from the raw feed and validates
+// the round against the configured heartbeat.
+type chainlinkSource struct {
+	client    onchain.Client
+	heartbeat time.Duration
+}
+
+func (s *chainlinkSource) Name() string { return PriceSourceChainlink }
+
+func (s *chainlinkSource) Fetch(ctx context.Context) (*model.PriceSample, error) {
+	round, err := s.client.GetLatestRound(ctx)
+	if err != nil {
+		return nil, err
+	}
+	sample := &model.PriceSample{
+		Source:    s.Name(),
+		Price:     round.Price,
+		UpdatedAt: round.UpdatedAt,
+		RoundID:   round.RoundID,
+	}
+	if warnings := validateRound(round, s.heartbeat, time.Now()); len(warnings) > 0 {
+		sample.Stale = true
+		sample.Warnings = warnings
+	}
+	return sample, nil
+}
+
+// dexTWAPSource derives the price from the time-weighted average tick of a
+// V3 WBNB/stable pool, read via eth_call observe().
+type dexTWAPSource struct {
+	client        onchain.Client
+	pool          string
+	window        time.Duration
+	baseIsToken0  bool
+	baseDecimals  int64
+	quoteDecimals int64
+}
+
+func (s *dexTWAPSource) Name() string { return PriceSourceDexTWAP }
+
+func (s *dexTWAPSource) Fetch(ctx context.Context) (*model.PriceSample, error) {
+	window := uint32(s.window / time.Second)
+	tick, err := s.client.GetV3TWAPTick(ctx, s.pool, window)
+	if err != nil {
+		return nil, err
+	}
+	price, err := tickToPrice(tick, s.baseIsToken0, s.baseDecimals, s.quoteDecimals)
+	if err != nil {
+		return nil, err
+	}
+	return &model.PriceSample{
+		Source:    s.Name(),
+		Price:     price.String(),
+		UpdatedAt: uint64(time.Now().Unix()),
+	}, nil
+}
+
+// tickToPrice converts a V3 tick into the base token price in quote tokens
+// with 18 decimals. 1.0001^tick is the raw token1/token0 ratio; it is
+// inverted when the base token is token1 and then adjusted for decimals.
+func tickToPrice(tick int64, baseIsToken0 bool, baseDecimals, quoteDecimals int64) (*big.Int, error) {
+	ratio := math.Pow(1.0001, float64(tick))
+	if !baseIsToken0 {
+		ratio = 1 / ratio
+	}
+	if math.IsInf(ratio, 0) || math.IsNaN(ratio) || ratio <= 0 {
+		return nil, fmt.Errorf("tick %d out of range", tick)
+	}
+
+	// raw ratio is quote units per base unit; scale to whole tokens then to 1e18.
+	price := new(big.Float).SetFloat64(ratio)
+	price.Mul(price, new(big.Float).SetFloat64(math.Pow10(int(baseDecimals-quoteDecimals))))
+	price.Mul(price, new(big.Float).SetInt(oneEther))
+
+	out, _ := price.Int(nil)
+	if out.Sign() <= 0 {
+		return nil, fmt.Errorf("tick %d gives zero price", tick)
+	}
+	return out, nil
+}
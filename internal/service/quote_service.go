@@ -4,40 +4,51 @@ import (
 	"context"
 	"fmt"
 	"math/big"
+	"time"
 
+	"github.com/cina_dex_backend/internal/config"
 	"github.com/cina_dex_backend/internal/model"
 	"github.com/cina_dex_backend/internal/onchain"
+	"github.com/cina_dex_backend/internal/tracing"
+	"github.com/ethereum/go-ethereum/common"
 )
 
 // QuoteService exposes read-only risk / quote related helpers.
 type QuoteService interface {
 	// QuoteBorrowCollateral computes the required BNB collateral (wei)
 	// for a desired USDT borrow amount (6 decimals, as decimal string).
-	QuoteBorrowCollateral(ctx context.Context, amount string) (*model.BorrowQuote, error)
+	// If duration (seconds) is non-zero, the expected repayment terms are included.
+	QuoteBorrowCollateral(ctx context.Context, amount string, duration uint64) (*model.BorrowQuote, error)
+	// QuoteRepay breaks down repayment of the given loans into principal and
+	// interest. If sender is non-empty, its USDT balance/allowance shortfall is included.
+	QuoteRepay(ctx context.Context, sender string, loanIDs []uint64) (*model.RepayQuote, error)
 }
 
 // quoteService is the default implementation of QuoteService.
 type quoteService struct {
-	client onchain.Client
-	cache  *StateCache
+	client          onchain.Client
+	prices          PriceService
+	cache           *StateCache
+	interestRateBps int64
 }
 
 // NewQuoteService constructs a QuoteService backed by the on-chain client.
-func NewQuoteService(c onchain.Client, cache *StateCache) QuoteService {
+func NewQuoteService(cfg *config.Config, c onchain.Client, prices PriceService, cache *StateCache) QuoteService {
+	rate := int64(defaultInterestRateBps)
+	if cfg.InterestRateBps > 0 {
+		rate = cfg.InterestRateBps
+	}
 	return &quoteService{
-		client: c,
-		cache:  cache,
+		client:          c,
+		prices:          prices,
+		cache:           cache,
+		interestRateBps: rate,
 	}
 }
 
-// Risk parameters mirrored from the LendingPool contract / documentation.
-// If these are changed on-chain, they should be updated here accordingly.
-const (
-	maxLTVPercent = 75 // 75%
-)
-
 // QuoteBorrowCollateral computes the required BNB collateral for a given borrow amount.
 // - amount: USDT principal in smallest units (6 decimals), as a decimal string.
+// - duration: loan duration in seconds; 0 skips the repayment terms.
 // The calculation uses:
 //
 //	collateralWei >= amountUsd / LTV / priceBnbUsd
@@ -45,8 +56,15 @@ const (
 // where:
 //   - amountUsd: 18-decimals USD value of the borrow amount (by scaling 6 -> 18)
 //   - LTV: maxLTVPercent%
-//   - priceBnbUsd: BNB/USD price from ChainlinkOracle.getPrice(address(0)), 18 decimals.
-func (s *quoteService) QuoteBorrowCollateral(ctx context.Context, amount string) (*model.BorrowQuote, error) {
+//   - priceBnbUsd: BNB/USD oracle price the contract enforces, 18 decimals.
+//
+// With a duration, interest follows the contract's fixed-rate model:
+//
+//	interest = amount * rateBps * duration / (10000 * secondsPerYear)
+func (s *quoteService) QuoteBorrowCollateral(ctx context.Context, amount string, duration uint64) (_ *model.BorrowQuote, err error) {
+	ctx, span := tracing.Start(ctx, "QuoteService.QuoteBorrowCollateral")
+	defer func() { tracing.End(span, err) }()
+
 	if amount == "" {
 		return nil, fmt.Errorf("amount is required")
 	}
@@ -59,22 +77,13 @@ func (s *quoteService) QuoteBorrowCollateral(ctx context.Context, amount string)
 		return nil, fmt.Errorf("amount must be positive")
 	}
 
-	var (
-		price *big.Int
-		err   error
-	)
-
-	// Prefer cached price if available, fall back to on-chain call.
-	if s.cache != nil {
-		if p, ok := s.cache.GetNativePrice(); ok {
-			price = p
-		}
+	priceInfo, err := s.nativePrice(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("get native price: %w", err)
 	}
-	if price == nil {
-		price, err = s.client.GetNativePrice(ctx)
-		if err != nil {
-			return nil, fmt.Errorf("get native price: %w", err)
-		}
+	price, err := parseBig(priceInfo.Price)
+	if err != nil {
+		return nil, fmt.Errorf("invalid native price: %w", err)
 	}
 	if price.Sign() <= 0 {
 		return nil, fmt.Errorf("oracle returned non-positive price")
@@ -106,10 +115,218 @@ func (s *quoteService) QuoteBorrowCollateral(ctx context.Context, amount string)
 		quotient.Add(quotient, big.NewInt(1))
 	}
 
-	return &model.BorrowQuote{
-		BorrowAmount:  amt.String(),
-		CollateralWei: quotient.String(),
-		BnbUsdPrice:   price.String(),
-		MaxLTVPercent: fmt.Sprintf("%d", maxLTVPercent),
+	quote := &model.BorrowQuote{
+		BorrowAmount:                amt.String(),
+		CollateralWei:               quotient.String(),
+		BnbUsdPrice:                 price.String(),
+		MaxLTVPercent:               fmt.Sprintf("%d", maxLTVPercent),
+		PriceStale:                  priceInfo.Stale,
+		PriceDeviating:              priceInfo.Deviating,
+		PriceUnsafe:                 priceInfo.Unsafe,
+		PriceCheckedAt:              priceInfo.CheckedAt,
+		LiquidationThresholdPercent: fmt.Sprintf("%d", liquidationThresholdPercent),
+	}
+
+	// The debt tracked by getLoanHealth is the full repayment amount, so the
+	// initial LTV and liquidation price include interest when a duration is given.
+	debt := amt
+	if duration > 0 {
+		interest := fixedInterest(amt, s.interestRateBps, duration)
+		debt = new(big.Int).Add(amt, interest)
+
+		quote.Duration = duration
+		quote.InterestRateBps = fmt.Sprintf("%d", s.interestRateBps)
+		quote.Interest = interest.String()
+		quote.RepaymentAmount = debt.String()
+		quote.AprPercent = impliedAPRPercent(amt, interest, duration)
+		quote.MaturityTime = uint64(time.Now().Unix()) + duration
+	}
+
+	if ltv := computeLTV(debt, quotient, price); ltv != nil {
+		quote.InitialLTV = ltv.String()
+		quote.InitialLtvPercent = formatRatioPercent(ltv)
+	}
+	quote.LiquidationPrice = liquidationPrice(debt, quotient).String()
+
+	ps, err := s.poolState(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("get pool state: %w", err)
+	}
+	available, err := parseBig(ps.AvailableLiquidity)
+	if err != nil {
+		return nil, fmt.Errorf("invalid availableLiquidity: %w", err)
+	}
+	quote.AvailableLiquidity = available.String()
+	quote.LiquiditySufficient = available.Cmp(amt) >= 0
+
+	return quote, nil
+}
+
+// nativePrice prefers the cached validated price and falls back to a live read.
+func (s *quoteService) nativePrice(ctx context.Context) (*model.PriceInfo, error) {
+	if s.cache != nil {
+		if info, ok := s.cache.GetPriceInfo(); ok {
+			return info, nil
+		}
+	}
+	return s.prices.GetNativePrice(ctx)
+}
+
+// poolState prefers the cached pool state and falls back to an on-chain read.
+func (s *quoteService) poolState(ctx context.Context) (*model.PoolState, error) {
+	if s.cache != nil {
+		if ps, ok := s.cache.GetPoolState(); ok {
+			return ps, nil
+		}
+	}
+	return livePoolState(ctx, s.client)
+}
+
+// QuoteRepay reads each loan from loans(id) and computes:
+//
+//	interest = repaymentAmount - principal
+//	APR      = interest / principal * secondsPerYear / duration
+//
+// Inactive loans are rejected since they can no longer be repaid.
+func (s *quoteService) QuoteRepay(ctx context.Context, sender string, loanIDs []uint64) (_ *model.RepayQuote, err error) {
+	ctx, span := tracing.Start(ctx, "QuoteService.QuoteRepay", tracing.Address(sender), tracing.LoanCount(len(loanIDs)))
+	defer func() { tracing.End(span, err) }()
+
+	if len(loanIDs) == 0 {
+		return nil, fmt.Errorf("at least one loanId is required")
+	}
+	if sender != "" && !isHexAddress(sender) {
+		return nil, fmt.Errorf("invalid sender address: %s", sender)
+	}
+
+	var (
+		totalPrincipal  = new(big.Int)
+		totalInterest   = new(big.Int)
+		totalRepayment  = new(big.Int)
+		totalCollateral = new(big.Int)
+		now             = uint64(time.Now().Unix())
+		seen            = make(map[uint64]bool, len(loanIDs))
+	)
+
+	quotes := make([]*model.LoanRepayQuote, 0, len(loanIDs))
+	for _, id := range loanIDs {
+		if seen[id] {
+			return nil, fmt.Errorf("duplicate loanId %d", id)
+		}
+		seen[id] = true
+
+		loan, err := s.client.GetLoan(ctx, id)
+		if err != nil {
+			return nil, fmt.Errorf("read loan %d: %w", id, err)
+		}
+		if !loan.IsActive {
+			return nil, fmt.Errorf("loan %d is not active", id)
+		}
+
+		q, err := buildLoanRepayQuote(loan, now)
+		if err != nil {
+			return nil, fmt.Errorf("loan %d: %w", id, err)
+		}
+		quotes = append(quotes, q)
+
+		// values were validated by buildLoanRepayQuote
+		principal, _ := parseBig(q.Principal)
+		interest, _ := parseBig(q.Interest)
+		repayment, _ := parseBig(q.RepaymentAmount)
+		collateral, _ := parseBig(q.CollateralReturned)
+		totalPrincipal.Add(totalPrincipal, principal)
+		totalInterest.Add(totalInterest, interest)
+		totalRepayment.Add(totalRepayment, repayment)
+		totalCollateral.Add(totalCollateral, collateral)
+	}
+
+	quote := &model.RepayQuote{
+		Loans:                   quotes,
+		TotalPrincipal:          totalPrincipal.String(),
+		TotalInterest:           totalInterest.String(),
+		TotalRepayment:          totalRepayment.String(),
+		TotalCollateralReturned: totalCollateral.String(),
+	}
+
+	if sender == "" {
+		return quote, nil
+	}
+
+	balance, err := s.client.GetUSDTBalance(ctx, sender)
+	if err != nil {
+		return nil, fmt.Errorf("get usdt balance: %w", err)
+	}
+	allowance, err := s.client.GetUSDTAllowance(ctx, sender)
+	if err != nil {
+		return nil, fmt.Errorf("get usdt allowance: %w", err)
+	}
+
+	quote.Sender = common.HexToAddress(sender).Hex()
+	quote.UsdtBalance = balance.String()
+	quote.UsdtAllowance = allowance.String()
+	quote.BalanceShortfall = shortfall(totalRepayment, balance).String()
+	quote.AllowanceShortfall = shortfall(totalRepayment, allowance).String()
+
+	return quote, nil
+}
+
+// buildLoanRepayQuote derives the principal/interest split and payoff timing of a loan.
+func buildLoanRepayQuote(loan *model.Loan, now uint64) (*model.LoanRepayQuote, error) {
+	principal, err := parseBig(loan.Principal)
+	if err != nil {
+		return nil, fmt.Errorf("invalid principal on-chain: %w", err)
+	}
+	repayment, err := parseBig(loan.RepaymentAmount)
+	if err != nil {
+		return nil, fmt.Errorf("invalid repaymentAmount on-chain: %w", err)
+	}
+	collateral, err := parseBig(loan.CollateralAmount)
+	if err != nil {
+		return nil, fmt.Errorf("invalid collateralAmount on-chain: %w", err)
+	}
+
+	interest := new(big.Int).Sub(repayment, principal)
+	if interest.Sign() < 0 {
+		interest.SetInt64(0)
+	}
+
+	maturity := loan.StartTime + loan.Duration
+	var remaining uint64
+	if maturity > now {
+		remaining = maturity - now
+	}
+
+	return &model.LoanRepayQuote{
+		LoanID:             loan.ID,
+		Principal:          principal.String(),
+		Interest:           interest.String(),
+		RepaymentAmount:    repayment.String(),
+		AprPercent:         impliedAPRPercent(principal, interest, loan.Duration),
+		CollateralReturned: collateral.String(),
+		StartTime:          loan.StartTime,
+		Duration:           loan.Duration,
+		MaturityTime:       maturity,
+		SecondsToMaturity:  remaining,
+		IsOverdue:          now > maturity,
 	}, nil
 }
+
+// impliedAPRPercent annualizes interest/principal over duration seconds and
+// formats it as a percentage with 2 decimals, e.g. "10.00".
+func impliedAPRPercent(principal, interest *big.Int, duration uint64) string {
+	if principal.Sign() <= 0 || duration == 0 {
+		return "0.00"
+	}
+	apr := new(big.Rat).SetFrac(interest, principal)
+	apr.Mul(apr, new(big.Rat).SetFrac64(secondsPerYear*100, int64(duration)))
+	return apr.FloatString(2)
+}
+
+// shortfall returns max(need - have, 0).
+func shortfall(need, have *big.Int) *big.Int {
+	d := new(big.Int).Sub(need, have)
+	if d.Sign() < 0 {
+		return d.SetInt64(0)
+	}
+	return d
+}
@@ -0,0 +1,200 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/cina_dex_backend/internal/config"
+	"github.com/cina_dex_backend/internal/health"
+	"github.com/cina_dex_backend/internal/metrics"
+	"github.com/cina_dex_backend/internal/model"
+	"github.com/cina_dex_backend/internal/onchain"
+)
+
+// ReadinessService checks whether the service can serve traffic.
+type ReadinessService interface {
+	// Ready checks the RPC endpoint, chain id, head block lag, cache
+	// freshness, the oracle price and background workers.
+	Ready(ctx context.Context) *model.Readiness
+}
+
+type readinessService struct {
+	client  onchain.Client
+	cache   *StateCache
+	chainID int64
+	maxLag  time.Duration
+	timeout time.Duration
+}
+
+// NewReadinessService constructs a ReadinessService; cache may be nil.
+func NewReadinessService(cfg *config.Config, client onchain.Client, cache *StateCache) ReadinessService {
+	return &readinessService{
+		client:  client,
+		cache:   cache,
+		chainID: cfg.ChainConfig.ChainID,
+		maxLag:  cfg.Health.MaxHeadLag,
+		timeout: cfg.Health.CheckTimeout,
+	}
+}
+
+// Ready runs the RPC checks concurrently. A dead or wrong RPC endpoint, a
+// stuck head block or a failing critical worker is down; a stale cache, an
+// unsafe price or a failing non-critical worker only degrades the service,
+// since every instance would be affected alike.
+func (s *readinessService) Ready(ctx context.Context) *model.Readiness {
+	var (
+		wg              sync.WaitGroup
+		rpc, head, chID *model.ComponentStatus
+	)
+	wg.Add(2)
+	go func() {
+		defer wg.Done()
+		rpc, head = s.checkHead(ctx)
+	}()
+	go func() {
+		defer wg.Done()
+		chID = s.checkChainID(ctx)
+	}()
+	wg.Wait()
+
+	components := []*model.ComponentStatus{rpc, chID, head}
+	components = append(components, s.checkCache()...)
+	components = append(components, s.checkOracle())
+	components = append(components, health.Workers()...)
+	if st := health.Shutdown(); st != nil {
+		components = append(components, st)
+	}
+
+	r := &model.Readiness{
+		Status:     model.HealthOK,
+		CheckedAt:  time.Now().Unix(),
+		Components: components,
+	}
+	for _, c := range components {
+		if healthRank(c.Status) > healthRank(r.Status) {
+			r.Status = c.Status
+		}
+	}
+	r.Ready = r.Status != model.HealthDown
+	return r
+}
+
+func healthRank(status string) int {
+	switch status {
+	case model.HealthDown:
+		return 2
+	case model.HealthDegraded:
+		return 1
+	}
+	return 0
+}
+
+// checkHead reads the head block, reporting RPC reachability and how far the
+// head trails wall-clock time.
+func (s *readinessService) checkHead(ctx context.Context) (rpc, head *model.ComponentStatus) {
+	ctx, cancel := context.WithTimeout(ctx, s.timeout)
+	defer cancel()
+
+	rpc = &model.ComponentStatus{Name: "rpc", Status: model.HealthOK}
+	head = &model.ComponentStatus{Name: "head_block", Status: model.HealthOK}
+
+	start := time.Now()
+	ref, err := s.client.HeadBlock(ctx)
+	rpc.LatencyMs = time.Since(start).Milliseconds()
+	if err != nil {
+		rpc.Status, rpc.Message = model.HealthDown, err.Error()
+		head.Status, head.Message = model.HealthDown, "rpc unreachable"
+		return rpc, head
+	}
+
+	lag := time.Since(time.Unix(int64(ref.Time), 0))
+	if lag < 0 {
+		lag = 0
+	}
+	head.Details = map[string]interface{}{
+		"number":     ref.Number,
+		"time":       ref.Time,
+		"lagSeconds": int64(lag / time.Second),
+	}
+	if lag > s.maxLag {
+		head.Status = model.HealthDown
+		head.Message = fmt.Sprintf("head block is %s old (max %s)", lag.Round(time.Second), s.maxLag)
+	}
+	return rpc, head
+}
+
+func (s *readinessService) checkChainID(ctx context.Context) *model.ComponentStatus {
+	ctx, cancel := context.WithTimeout(ctx, s.timeout)
+	defer cancel()
+
+	st := &model.ComponentStatus{Name: "chain_id", Status: model.HealthOK}
+	id, err := s.client.ChainID(ctx)
+	if err != nil {
+		st.Status, st.Message = model.HealthDown, err.Error()
+		return st
+	}
+	st.Details = map[string]interface{}{"chainId": id, "expected": s.chainID}
+	if s.chainID != 0 && id != uint64(s.chainID) {
+		st.Status = model.HealthDown
+		st.Message = fmt.Sprintf("rpc serves chain %d, expected %d", id, s.chainID)
+	}
+	return st
+}
+
+// checkCache reports the age of the cached pool state and price. Reads fall
+// back to the chain when they are missing or expired, so this only degrades.
+func (s *readinessService) checkCache() []*model.ComponentStatus {
+	if s.cache == nil {
+		return nil
+	}
+	entry := func(name string, st *model.CacheStatus, ok bool) *model.ComponentStatus {
+		c := &model.ComponentStatus{Name: name, Status: model.HealthOK}
+		switch {
+		case !ok:
+			c.Status, c.Message = model.HealthDegraded, "not refreshed yet"
+		case st.Expired:
+			c.Status, c.Message = model.HealthDegraded, fmt.Sprintf("expired, %ds old", st.AgeSeconds)
+		}
+		if ok {
+			c.Details = map[string]interface{}{"fetchedAt": st.FetchedAt, "blockNumber": st.BlockNumber, "ageSeconds": st.AgeSeconds}
+		}
+		return c
+	}
+	pool, poolOK := s.cache.PoolStateStatus()
+	price, priceOK := s.cache.PriceStatus()
+	return []*model.ComponentStatus{
+		entry("cache:pool_state", pool, poolOK),
+		entry("cache:price", price, priceOK),
+	}
+}
+
+// checkOracle reports the checks of the last validated price.
+func (s *readinessService) checkOracle() *model.ComponentStatus {
+	st := &model.ComponentStatus{Name: "oracle", Status: model.HealthOK}
+	if s.cache == nil {
+		return st
+	}
+	info, ok := s.cache.GetPriceInfo()
+	if !ok {
+		st.Status, st.Message = model.HealthDegraded, "no validated price"
+		return st
+	}
+	st.Details = map[string]interface{}{
+		"stale":     info.Stale,
+		"deviating": info.Deviating,
+		"updatedAt": info.UpdatedAt,
+	}
+	if info.Unsafe {
+		st.Status = model.HealthDegraded
+		st.Message = fmt.Sprintf("price flagged unsafe: %v", info.Warnings)
+	}
+	return st
+}
+
+// jobDone records a background job run for metrics and readiness.
+func jobDone(job string, err error) {
+	metrics.JobDone(job, err)
+	health.Report(job, err)
+}
@@ -0,0 +1,302 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/big"
+	"time"
+
+	"github.com/cina_dex_backend/internal/model"
+)
+
+// RevenueService accounts for the interest the pool earned from repays and
+// liquidations, and for interest still outstanding on active loans.
+type RevenueService interface {
+	// Revenue aggregates indexed repays / liquidations in [from, to] per UTC day.
+	Revenue(ctx context.Context, from, to time.Time) (*model.PoolRevenue, error)
+}
+
+// ErrInvalidRevenueRange is returned by Revenue when from is not before to.
+var ErrInvalidRevenueRange = errors.New("invalid revenue range")
+
+type revenueService struct {
+	events *EventIndexer
+	loans  *LoanScanner
+	prices PriceHistoryService
+	cache  *StateCache
+}
+
+// NewRevenueService constructs a RevenueService. prices and cache provide
+// BNB/USD for liquidation outcomes and underwater loans and may be nil.
+func NewRevenueService(events *EventIndexer, loans *LoanScanner, prices PriceHistoryService, cache *StateCache) RevenueService {
+	return &revenueService{
+		events: events,
+		loans:  loans,
+		prices: prices,
+		cache:  cache,
+	}
+}
+
+// revenueTotals accumulates interest and liquidation outcomes.
+type revenueTotals struct {
+	repayInterest       *big.Int
+	liquidationInterest *big.Int
+	shortfall           *big.Int
+	bonus               *big.Int
+}
+
+func newRevenueTotals() *revenueTotals {
+	return &revenueTotals{
+		repayInterest:       new(big.Int),
+		liquidationInterest: new(big.Int),
+		shortfall:           new(big.Int),
+		bonus:               new(big.Int),
+	}
+}
+
+func (t *revenueTotals) interest() *big.Int {
+	return new(big.Int).Add(t.repayInterest, t.liquidationInterest)
+}
+
+// Revenue books, for every repay and liquidation, interest = amount repaid -
+// principal. The principal comes from the loan's Borrow event, or from the
+// loan itself when it was borrowed before the indexer start block.
+//
+// Liquidators repay the full debt, so the pool itself is made whole; the
+// shortfall reports debt the seized collateral was worth less than at the
+// oracle price of the time, and the bonus what it was worth above the debt.
+func (s *revenueService) Revenue(ctx context.Context, from, to time.Time) (*model.PoolRevenue, error) {
+	if !from.Before(to) {
+		return nil, fmt.Errorf("%w: from must be before to", ErrInvalidRevenueRange)
+	}
+
+	events := s.events.Events()
+	principals := make(map[uint64]*big.Int)
+	for _, ev := range events {
+		if ev.Type == model.ActivityBorrow && ev.LoanID != nil {
+			if p, err := parseBig(ev.Amount); err == nil {
+				principals[*ev.LoanID] = p
+			}
+		}
+	}
+
+	out := &model.PoolRevenue{
+		From:         from.Unix(),
+		To:           to.Unix(),
+		Days:         make([]*model.RevenueDay, 0),
+		IndexedBlock: s.events.IndexedBlock(),
+	}
+
+	var (
+		total       = newRevenueTotals()
+		day         *model.RevenueDay
+		dayTotals   *revenueTotals
+		unpriced    int
+		unknownLoan int
+		scanned     bool
+	)
+	closeDay := func() {
+		if day == nil {
+			return
+		}
+		day.RepayInterest = dayTotals.repayInterest.String()
+		day.LiquidationInterest = dayTotals.liquidationInterest.String()
+		day.Interest = dayTotals.interest().String()
+		day.LiquidationShortfall = dayTotals.shortfall.String()
+		day.CumulativeInterest = total.interest().String()
+		day.CumulativeShortfall = total.shortfall.String()
+		out.Days = append(out.Days, day)
+	}
+
+	for _, ev := range events {
+		if (ev.Type != model.ActivityRepay && ev.Type != model.ActivityLiquidate) || ev.LoanID == nil {
+			continue
+		}
+		t := time.Unix(int64(ev.BlockTime), 0)
+		if t.Before(from) || t.After(to) {
+			continue
+		}
+		repaid, err := parseBig(ev.Amount)
+		if err != nil {
+			continue
+		}
+
+		principal, ok := principals[*ev.LoanID]
+		if !ok && !scanned && s.loans != nil {
+			scanned = true
+			if err := s.loadPrincipals(ctx, principals); err != nil {
+				out.Warnings = append(out.Warnings, fmt.Sprintf("load loans: %v", err))
+			}
+			principal, ok = principals[*ev.LoanID]
+		}
+		if !ok {
+			unknownLoan++
+			continue
+		}
+
+		date := t.UTC().Format("2006-01-02")
+		if day == nil || day.Date != date {
+			closeDay()
+			day = &model.RevenueDay{Date: date}
+			dayTotals = newRevenueTotals()
+		}
+
+		interest := new(big.Int).Sub(repaid, principal)
+		if ev.Type == model.ActivityRepay {
+			day.Repays++
+			dayTotals.repayInterest.Add(dayTotals.repayInterest, interest)
+			total.repayInterest.Add(total.repayInterest, interest)
+			continue
+		}
+
+		day.Liquidations++
+		dayTotals.liquidationInterest.Add(dayTotals.liquidationInterest, interest)
+		total.liquidationInterest.Add(total.liquidationInterest, interest)
+
+		seized, err := parseBig(ev.CollateralAmount)
+		price, ok := s.priceAt(t)
+		if err != nil || !ok {
+			unpriced++
+			continue
+		}
+		value := usdToUSDT(collateralValueUSD(seized, price))
+		if gap := new(big.Int).Sub(repaid, value); gap.Sign() > 0 {
+			dayTotals.shortfall.Add(dayTotals.shortfall, gap)
+			total.shortfall.Add(total.shortfall, gap)
+		} else {
+			total.bonus.Add(total.bonus, gap.Neg(gap))
+		}
+	}
+	closeDay()
+
+	out.RepayInterest = total.repayInterest.String()
+	out.LiquidationInterest = total.liquidationInterest.String()
+	out.TotalInterest = total.interest().String()
+	out.LiquidationShortfall = total.shortfall.String()
+	out.LiquidatorBonus = total.bonus.String()
+
+	if unknownLoan > 0 {
+		out.Warnings = append(out.Warnings, fmt.Sprintf("%d repays/liquidations skipped: principal unknown", unknownLoan))
+	}
+	if unpriced > 0 {
+		out.Warnings = append(out.Warnings, fmt.Sprintf("%d liquidations without a recorded BNB/USD price; shortfall/bonus excludes them", unpriced))
+	}
+
+	outstanding, err := s.outstanding(ctx, time.Now())
+	if err != nil {
+		out.Warnings = append(out.Warnings, fmt.Sprintf("outstanding interest unavailable: %v", err))
+	} else {
+		out.Outstanding = outstanding
+	}
+
+	return out, nil
+}
+
+// loadPrincipals fills principals of loans that have no indexed Borrow event.
+func (s *revenueService) loadPrincipals(ctx context.Context, principals map[uint64]*big.Int) error {
+	loans, err := s.loans.Loans(ctx)
+	if err != nil {
+		return err
+	}
+	for _, loan := range loans {
+		if _, ok := principals[loan.ID]; ok {
+			continue
+		}
+		if p, err := parseBig(loan.Principal); err == nil {
+			principals[loan.ID] = p
+		}
+	}
+	return nil
+}
+
+// outstanding sums scheduled and linearly accrued interest of active loans
+// and the debt of loans worth less than their collateral at the current price.
+func (s *revenueService) outstanding(ctx context.Context, now time.Time) (*model.OutstandingInterest, error) {
+	if s.loans == nil {
+		return nil, fmt.Errorf("loan scanner not configured")
+	}
+	loans, err := s.loans.ActiveLoans(ctx)
+	if err != nil {
+		return nil, err
+	}
+	price, havePrice := s.currentPrice()
+
+	var (
+		principalSum = new(big.Int)
+		scheduled    = new(big.Int)
+		accrued      = new(big.Int)
+		underwater   = new(big.Int)
+		underwaterN  int
+	)
+	for _, loan := range loans {
+		principal, err1 := parseBig(loan.Principal)
+		repayment, err2 := parseBig(loan.RepaymentAmount)
+		if err1 != nil || err2 != nil {
+			continue
+		}
+		interest := new(big.Int).Sub(repayment, principal)
+		principalSum.Add(principalSum, principal)
+		scheduled.Add(scheduled, interest)
+
+		elapsed := uint64(0)
+		if ts := uint64(now.Unix()); ts > loan.StartTime {
+			elapsed = ts - loan.StartTime
+		}
+		if loan.Duration == 0 || elapsed >= loan.Duration {
+			accrued.Add(accrued, interest)
+		} else {
+			a := new(big.Int).Mul(interest, new(big.Int).SetUint64(elapsed))
+			accrued.Add(accrued, a.Quo(a, new(big.Int).SetUint64(loan.Duration)))
+		}
+
+		if havePrice {
+			collateral, err := parseBig(loan.CollateralAmount)
+			if err != nil {
+				continue
+			}
+			value := usdToUSDT(collateralValueUSD(collateral, price))
+			if gap := new(big.Int).Sub(repayment, value); gap.Sign() > 0 {
+				underwaterN++
+				underwater.Add(underwater, gap)
+			}
+		}
+	}
+
+	return &model.OutstandingInterest{
+		ActiveLoans:       len(loans),
+		Principal:         principalSum.String(),
+		ScheduledInterest: scheduled.String(),
+		AccruedInterest:   accrued.String(),
+		UnderwaterLoans:   underwaterN,
+		UnderwaterDebt:    underwater.String(),
+	}, nil
+}
+
+func (s *revenueService) priceAt(t time.Time) (*big.Int, bool) {
+	if s.prices == nil {
+		return nil, false
+	}
+	return s.prices.PriceAt(PriceSourceOracle, t)
+}
+
+// currentPrice returns the validated price cached by the state updater.
+func (s *revenueService) currentPrice() (*big.Int, bool) {
+	if s.cache == nil {
+		return nil, false
+	}
+	info, ok := s.cache.GetPriceInfo()
+	if !ok {
+		return nil, false
+	}
+	price, err := parseBig(info.Price)
+	if err != nil || price.Sign() <= 0 {
+		return nil, false
+	}
+	return price, true
+}
+
+// usdToUSDT converts an 18-decimal USD amount to 6-decimal USDT, rounding down.
+func usdToUSDT(amt *big.Int) *big.Int {
+	return new(big.Int).Quo(amt, usdtTo18)
+}
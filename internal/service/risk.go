@@ -0,0 +1,112 @@
+package service
+
+import "math/big"
+
+// Risk parameters mirrored from the LendingPool contract / documentation.
+// If these are changed on-chain, they should be updated here accordingly.
+const (
+	maxLTVPercent               = 75  // 75%
+	liquidationThresholdPercent = 80  // 80%, loans above this LTV can be liquidated
+	liquidationBonusPercent     = 104 // liquidator receives debt value * 104% in collateral
+
+	// defaultInterestRateBps is the fixed annual borrow rate charged by the
+	// contract (10%). It can be overridden with INTEREST_RATE_BPS.
+	defaultInterestRateBps = 1000
+)
+
+// secondsPerYear is used to annualize fixed loan interest (365 days).
+const secondsPerYear = 365 * 24 * 60 * 60
+
+var (
+	oneEther  = new(big.Int).Exp(big.NewInt(10), big.NewInt(18), nil) // 1e18
+	usdtTo18  = new(big.Int).Exp(big.NewInt(10), big.NewInt(12), nil) // 1e12, scales 6 -> 18 decimals
+	bpsDenom  = big.NewInt(10000)
+	hundred   = big.NewInt(100)
+	yearInSec = big.NewInt(secondsPerYear)
+)
+
+// usdtToUSD converts a 6-decimal USDT amount to an 18-decimal USD amount.
+func usdtToUSD(amt *big.Int) *big.Int {
+	return new(big.Int).Mul(amt, usdtTo18)
+}
+
+// collateralValueUSD returns the 18-decimal USD value of collateralWei at
+// the given 18-decimal BNB/USD price.
+func collateralValueUSD(collateralWei, price *big.Int) *big.Int {
+	v := new(big.Int).Mul(collateralWei, price)
+	return v.Quo(v, oneEther)
+}
+
+// computeLTV mirrors getLoanHealth: debt value / collateral value with 18
+// decimals (1e18 = 100%). debt is the USDT repayment amount (6 decimals).
+// It returns nil when the collateral is worthless.
+func computeLTV(debt, collateralWei, price *big.Int) *big.Int {
+	collValue := collateralValueUSD(collateralWei, price)
+	if collValue.Sign() == 0 {
+		return nil
+	}
+	ltv := new(big.Int).Mul(usdtToUSD(debt), oneEther)
+	return ltv.Quo(ltv, collValue)
+}
+
+// isLiquidatableLTV reports whether an 18-decimal LTV exceeds the liquidation threshold.
+func isLiquidatableLTV(ltv *big.Int) bool {
+	if ltv == nil {
+		return true
+	}
+	threshold := new(big.Int).Mul(oneEther, big.NewInt(liquidationThresholdPercent))
+	threshold.Quo(threshold, hundred)
+	return ltv.Cmp(threshold) > 0
+}
+
+// liquidationPrice returns the 18-decimal BNB/USD price at which a loan with
+// the given debt (6 decimals) and collateral reaches the liquidation threshold:
+//
+//	price = debtUsd * 1e18 * 100 / (collateralWei * liquidationThresholdPercent)
+func liquidationPrice(debt, collateralWei *big.Int) *big.Int {
+	if collateralWei.Sign() == 0 {
+		return new(big.Int)
+	}
+	num := new(big.Int).Mul(usdtToUSD(debt), oneEther)
+	num.Mul(num, hundred)
+	den := new(big.Int).Mul(collateralWei, big.NewInt(liquidationThresholdPercent))
+	return ceilDiv(num, den)
+}
+
+// liquidationSeizure returns the collateral (wei) a liquidator receives for
+// repaying debt (6 decimals): debt value * liquidationBonusPercent / 100 at
+// price, capped at the loan's collateral.
+func liquidationSeizure(debt, collateralWei, price *big.Int) *big.Int {
+	seized := new(big.Int).Mul(usdtToUSD(debt), big.NewInt(liquidationBonusPercent))
+	seized.Mul(seized, oneEther)
+	seized.Quo(seized, new(big.Int).Mul(price, hundred))
+	if seized.Cmp(collateralWei) > 0 {
+		seized.Set(collateralWei)
+	}
+	return seized
+}
+
+// fixedInterest returns principal * rateBps * duration / (10000 * secondsPerYear),
+// rounded down like the contract's integer math.
+func fixedInterest(principal *big.Int, rateBps int64, duration uint64) *big.Int {
+	v := new(big.Int).Mul(principal, big.NewInt(rateBps))
+	v.Mul(v, new(big.Int).SetUint64(duration))
+	den := new(big.Int).Mul(bpsDenom, yearInSec)
+	return v.Quo(v, den)
+}
+
+// ceilDiv returns ceil(a / b) for positive b.
+func ceilDiv(a, b *big.Int) *big.Int {
+	q, r := new(big.Int).QuoRem(a, b, new(big.Int))
+	if r.Sign() > 0 {
+		q.Add(q, big.NewInt(1))
+	}
+	return q
+}
+
+// formatRatioPercent formats an 18-decimal ratio (1e18 = 100%) as a percentage
+// with 2 decimals, e.g. "75.00".
+func formatRatioPercent(ratio *big.Int) string {
+	r := new(big.Rat).SetFrac(new(big.Int).Mul(ratio, hundred), oneEther)
+	return r.FloatString(2)
+}
@@ -2,9 +2,11 @@ package service
 
 import (
 	"context"
+	"time"
 
 	"github.com/cina_dex_backend/internal/model"
 	"github.com/cina_dex_backend/internal/onchain"
+	"github.com/cina_dex_backend/internal/tracing"
 )
 
 // PoolService defines read operations related to the lending pool.
@@ -13,6 +15,11 @@ type PoolService interface {
 	GetUserPosition(ctx context.Context, address string) (*model.UserPosition, error)
 	// GetLenderPosition returns LP position and earnings info for a given address.
 	GetLenderPosition(ctx context.Context, address string) (*model.LenderPosition, error)
+	// GetLenderReport returns the lender's position with earnings and
+	// time-/money-weighted returns over [from, to].
+	GetLenderReport(ctx context.Context, address string, from, to time.Time) (*model.LenderReport, error)
+	// GetPoolAPY returns trailing 1d/7d/30d realized and forward estimated supply yields.
+	GetPoolAPY(ctx context.Context) (*model.PoolAPY, error)
 }
 
 // LoanService defines operations related to individual loans.
@@ -23,10 +30,14 @@ type LoanService interface {
 }
 
 // NewPoolService constructs a PoolService backed by the on-chain client.
-func NewPoolService(c onchain.Client, cache *StateCache) PoolService {
+// metrics, loans and events are used for yield and lender reporting and may be nil.
+func NewPoolService(c onchain.Client, cache *StateCache, metrics PoolMetricsService, loans *LoanScanner, events *EventIndexer) PoolService {
 	return &poolService{
-		client: c,
-		cache:  cache,
+		client:  c,
+		cache:   cache,
+		metrics: metrics,
+		loans:   loans,
+		events:  events,
 	}
 }
 
@@ -36,33 +47,54 @@ func NewLoanService(c onchain.Client) LoanService {
 }
 
 type poolService struct {
-	client onchain.Client
-	cache  *StateCache
+	client  onchain.Client
+	cache   *StateCache
+	metrics PoolMetricsService
+	loans   *LoanScanner
+	events  *EventIndexer
 }
 
-func (s *poolService) GetPoolState(ctx context.Context) (*model.PoolState, error) {
+func (s *poolService) GetPoolState(ctx context.Context) (_ *model.PoolState, err error) {
+	ctx, span := tracing.Start(ctx, "PoolService.GetPoolState")
+	defer func() { tracing.End(span, err) }()
+
 	if s.cache != nil {
 		if ps, ok := s.cache.GetPoolState(); ok {
 			return ps, nil
 		}
 	}
-	return s.client.GetPoolState(ctx)
+	return livePoolState(ctx, s.client)
+}
+
+// livePoolState reads getPoolState() from the chain, stamped with the read time.
+func livePoolState(ctx context.Context, c onchain.Client) (*model.PoolState, error) {
+	ps, err := c.GetPoolState(ctx)
+	if err != nil {
+		return nil, err
+	}
+	ps.FetchedAt = time.Now().Unix()
+	return ps, nil
 }
 
-func (s *poolService) GetUserPosition(ctx context.Context, address string) (*model.UserPosition, error) {
+func (s *poolService) GetUserPosition(ctx context.Context, address string) (_ *model.UserPosition, err error) {
+	ctx, span := tracing.Start(ctx, "PoolService.GetUserPosition", tracing.Address(address))
+	defer func() { tracing.End(span, err) }()
+
 	return s.client.GetUserPosition(ctx, address)
 }
 
-func (s *poolService) GetLenderPosition(ctx context.Context, address string) (*model.LenderPosition, error) {
+func (s *poolService) GetLenderPosition(ctx context.Context, address string) (_ *model.LenderPosition, err error) {
+	ctx, span := tracing.Start(ctx, "PoolService.GetLenderPosition", tracing.Address(address))
+	defer func() { tracing.End(span, err) }()
+
 	lp, err := s.client.GetLenderPosition(ctx, address)
 	if err != nil {
 		return nil, err
 	}
 
-	// TODO: replace this stub with real off-chain tracking of netDeposited (total deposits - total withdrawals).
-	lp.NetDeposited = "0"
-	lp.Interest = lp.UnderlyingBalance // until netDeposited is wired, treat全部为收益的上界展示
-
+	if err := fillLenderAccounting(lp, s.lenderFlows(address), s.events != nil); err != nil {
+		return nil, err
+	}
 	return lp, nil
 }
 
@@ -70,14 +102,23 @@ type loanService struct {
 	client onchain.Client
 }
 
-func (s *loanService) ListUserLoans(ctx context.Context, address string) ([]*model.Loan, error) {
+func (s *loanService) ListUserLoans(ctx context.Context, address string) (_ []*model.Loan, err error) {
+	ctx, span := tracing.Start(ctx, "LoanService.ListUserLoans", tracing.Address(address))
+	defer func() { tracing.End(span, err) }()
+
 	return s.client.ListUserLoans(ctx, address)
 }
 
-func (s *loanService) GetLoan(ctx context.Context, id uint64) (*model.Loan, error) {
+func (s *loanService) GetLoan(ctx context.Context, id uint64) (_ *model.Loan, err error) {
+	ctx, span := tracing.Start(ctx, "LoanService.GetLoan", tracing.LoanID(id))
+	defer func() { tracing.End(span, err) }()
+
 	return s.client.GetLoan(ctx, id)
 }
 
-func (s *loanService) GetLoanHealth(ctx context.Context, id uint64) (*model.LoanHealth, error) {
+func (s *loanService) GetLoanHealth(ctx context.Context, id uint64) (_ *model.LoanHealth, err error) {
+	ctx, span := tracing.Start(ctx, "LoanService.GetLoanHealth", tracing.LoanID(id))
+	defer func() { tracing.End(span, err) }()
+
 	return s.client.GetLoanHealth(ctx, id)
 }
@@ -0,0 +1,305 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"math/big"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/cina_dex_backend/internal/config"
+	"github.com/cina_dex_backend/internal/health"
+	"github.com/cina_dex_backend/internal/lifecycle"
+	"github.com/cina_dex_backend/internal/model"
+	"github.com/cina_dex_backend/internal/tracing"
+)
+
+// maxAtRiskLoans bounds the loans listed in a solvency report.
+const maxAtRiskLoans = 20
+
+// SolvencyService values every active loan at the oracle price and reports
+// bad debt against the pool's assets.
+type SolvencyService interface {
+	// Check revalues all active loans now, stores the report and raises
+	// alerts on status changes.
+	Check(ctx context.Context) (*model.SolvencyReport, error)
+	// Report returns the latest stored report, running a check if none exists.
+	Report(ctx context.Context) (*model.SolvencyReport, error)
+}
+
+type solvencyService struct {
+	loans   *LoanScanner
+	pool    PoolService
+	prices  PriceService
+	cache   *StateCache
+	alerter Alerter
+
+	criticalBps int64
+	repeat      time.Duration
+
+	mu          sync.Mutex
+	last        *model.SolvencyReport
+	alertStatus string
+	alertedAt   time.Time
+}
+
+// NewSolvencyService constructs a SolvencyService. The price is taken from
+// the cache when available and read through prices otherwise.
+func NewSolvencyService(cfg *config.Config, loans *LoanScanner, pool PoolService, prices PriceService, cache *StateCache, alerter Alerter) SolvencyService {
+	return &solvencyService{
+		loans:       loans,
+		pool:        pool,
+		prices:      prices,
+		cache:       cache,
+		alerter:     alerter,
+		criticalBps: cfg.Solvency.CriticalShortfallBps,
+		repeat:      cfg.Solvency.AlertRepeat,
+		alertStatus: model.SolvencyOK,
+	}
+}
+
+func (s *solvencyService) Report(ctx context.Context) (*model.SolvencyReport, error) {
+	s.mu.Lock()
+	last := s.last
+	s.mu.Unlock()
+	if last != nil {
+		return last, nil
+	}
+	return s.Check(ctx)
+}
+
+// Check classifies each active loan:
+//   - liquidatable: LTV above the liquidation threshold;
+//   - below bonus: collateral value < debt * liquidation bonus, so liquidators
+//     may no longer act;
+//   - bad debt: collateral value < debt.
+//
+// The status is critical when bad debt reaches CriticalShortfallBps of
+// TotalAssets, warning when any loan is liquidatable, below bonus or has bad
+// debt, and ok otherwise.
+func (s *solvencyService) Check(ctx context.Context) (_ *model.SolvencyReport, err error) {
+	ctx, span := tracing.Start(ctx, "SolvencyService.Check")
+	defer func() { tracing.End(span, err) }()
+
+	info, price, err := validatedPrice(ctx, s.cache, s.prices)
+	if err != nil {
+		return nil, err
+	}
+	ps, err := s.pool.GetPoolState(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("get pool state: %w", err)
+	}
+	assets, err := parseBig(ps.TotalAssets)
+	if err != nil {
+		return nil, fmt.Errorf("invalid totalAssets: %w", err)
+	}
+	loans, err := s.loans.ActiveLoans(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("scan loans: %w", err)
+	}
+
+	report := &model.SolvencyReport{
+		CheckedAt:   time.Now().Unix(),
+		Price:       price.String(),
+		PriceUnsafe: info.Unsafe,
+		ActiveLoans: len(loans),
+		TotalAssets: assets.String(),
+	}
+	if info.Unsafe {
+		report.Warnings = append(report.Warnings, "price is flagged unsafe; valuations may be wrong")
+	}
+
+	var (
+		totalDebt  = new(big.Int)
+		totalValue = new(big.Int)
+		badDebt    = new(big.Int)
+		rows       = make([]*model.SolvencyLoan, 0, len(loans))
+		ltvs       = make(map[uint64]*big.Int, len(loans))
+	)
+	for _, loan := range loans {
+		debt, err1 := parseBig(loan.RepaymentAmount)
+		collateral, err2 := parseBig(loan.CollateralAmount)
+		if err1 != nil || err2 != nil {
+			report.Warnings = append(report.Warnings, fmt.Sprintf("loan %d: invalid amounts", loan.ID))
+			continue
+		}
+		value := usdToUSDT(collateralValueUSD(collateral, price))
+		totalDebt.Add(totalDebt, debt)
+		totalValue.Add(totalValue, value)
+
+		row := &model.SolvencyLoan{
+			LoanID:          loan.ID,
+			Borrower:        loan.Borrower,
+			Debt:            debt.String(),
+			Collateral:      collateral.String(),
+			CollateralValue: value.String(),
+			Shortfall:       "0",
+		}
+
+		ltv := computeLTV(debt, collateral, price)
+		if ltv != nil {
+			row.LTV = ltv.String()
+			row.LtvPercent = formatRatioPercent(ltv)
+		}
+		ltvs[loan.ID] = ltv
+		row.Liquidatable = isLiquidatableLTV(ltv)
+
+		// value * 100 < debt * bonus
+		withBonus := new(big.Int).Mul(debt, big.NewInt(liquidationBonusPercent))
+		row.BelowBonus = new(big.Int).Mul(value, hundred).Cmp(withBonus) < 0
+
+		if gap := new(big.Int).Sub(debt, value); gap.Sign() > 0 {
+			row.Shortfall = gap.String()
+			badDebt.Add(badDebt, gap)
+			report.BadDebtLoans++
+		}
+		if row.Liquidatable {
+			report.LiquidatableLoans++
+		}
+		if row.BelowBonus {
+			report.BelowBonusLoans++
+		}
+		rows = append(rows, row)
+	}
+
+	// Highest LTV first; worthless collateral (nil LTV) sorts first.
+	sort.SliceStable(rows, func(i, j int) bool {
+		a, b := ltvs[rows[i].LoanID], ltvs[rows[j].LoanID]
+		if a == nil || b == nil {
+			return a == nil && b != nil
+		}
+		return a.Cmp(b) > 0
+	})
+	if len(rows) > maxAtRiskLoans {
+		rows = rows[:maxAtRiskLoans]
+	}
+	report.AtRisk = rows
+
+	report.TotalDebt = totalDebt.String()
+	report.TotalCollateralValue = totalValue.String()
+	report.BadDebt = badDebt.String()
+	shortfallBps := new(big.Int)
+	if assets.Sign() > 0 {
+		shortfallBps.Mul(badDebt, bpsDenom)
+		shortfallBps.Quo(shortfallBps, assets)
+	}
+	report.ShortfallBps = shortfallBps.String()
+
+	switch {
+	case badDebt.Sign() > 0 && shortfallBps.Cmp(big.NewInt(s.criticalBps)) >= 0:
+		report.Status = model.SolvencyCritical
+	case report.BadDebtLoans > 0 || report.LiquidatableLoans > 0 || report.BelowBonusLoans > 0:
+		report.Status = model.SolvencyWarning
+	default:
+		report.Status = model.SolvencyOK
+	}
+
+	s.mu.Lock()
+	s.last = report
+	s.mu.Unlock()
+
+	s.maybeAlert(ctx, report)
+	return report, nil
+}
+
+// validatedPrice returns the price cached by the state updater, reading
+// through prices when the cache is empty.
+func validatedPrice(ctx context.Context, cache *StateCache, prices PriceService) (*model.PriceInfo, *big.Int, error) {
+	var (
+		info *model.PriceInfo
+		ok   bool
+	)
+	if cache != nil {
+		info, ok = cache.GetPriceInfo()
+	}
+	if !ok {
+		var err error
+		if info, err = prices.GetNativePrice(ctx); err != nil {
+			return nil, nil, fmt.Errorf("get price: %w", err)
+		}
+	}
+	price, err := parseBig(info.Price)
+	if err != nil || price.Sign() <= 0 {
+		return nil, nil, fmt.Errorf("invalid price: %s", info.Price)
+	}
+	return info, price, nil
+}
+
+// maybeAlert alerts when the status changes, and repeats a non-ok status
+// every repeat interval.
+func (s *solvencyService) maybeAlert(ctx context.Context, r *model.SolvencyReport) {
+	if s.alerter == nil {
+		return
+	}
+
+	s.mu.Lock()
+	now := time.Now()
+	changed := r.Status != s.alertStatus
+	repeat := r.Status != model.SolvencyOK && s.repeat > 0 && now.Sub(s.alertedAt) >= s.repeat
+	if !changed && !repeat {
+		s.mu.Unlock()
+		return
+	}
+	prev := s.alertStatus
+	s.alertStatus = r.Status
+	s.alertedAt = now
+	s.mu.Unlock()
+
+	msg := fmt.Sprintf("pool solvency %s: %d bad-debt loans, bad debt %s USDT units (%s bps of assets), %d liquidatable, %d below liquidation bonus",
+		r.Status, r.BadDebtLoans, r.BadDebt, r.ShortfallBps, r.LiquidatableLoans, r.BelowBonusLoans)
+	if changed {
+		msg = fmt.Sprintf("%s (was %s)", msg, prev)
+	}
+	level := r.Status
+	if level == model.SolvencyOK {
+		level = "info"
+	}
+
+	if err := s.alerter.Alert(ctx, &model.Alert{
+		Source:    "solvency",
+		Level:     level,
+		Message:   msg,
+		CreatedAt: now.Unix(),
+		Data:      r,
+	}); err != nil {
+		slog.ErrorContext(ctx, "solvency monitor: send alert", "err", err)
+	}
+}
+
+// StartSolvencyMonitor launches a background goroutine that rechecks
+// solvency every interval and whenever the cache receives new state.
+func StartSolvencyMonitor(ctx context.Context, workers *lifecycle.Workers, svc SolvencyService, cache *StateCache, interval time.Duration) {
+	if svc == nil || interval <= 0 {
+		return
+	}
+	health.Register("solvency_monitor", 3*interval, false)
+	var updates <-chan struct{}
+	unsubscribe := func() {}
+	if cache != nil {
+		updates, unsubscribe = cache.Subscribe()
+	}
+
+	workers.Go("solvency monitor", func() {
+		defer unsubscribe()
+
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-ctx.Done():
+				slog.Info("solvency monitor stopped: context cancelled")
+				return
+			case <-ticker.C:
+			case <-updates:
+			}
+			_, err := svc.Check(ctx)
+			jobDone("solvency_monitor", err)
+			if err != nil {
+				slog.ErrorContext(ctx, "solvency monitor: check", "err", err)
+			}
+		}
+	})
+}
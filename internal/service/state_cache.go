@@ -3,37 +3,121 @@ package service
 import (
 	"math/big"
 	"sync"
+	"time"
 
+	"github.com/cina_dex_backend/internal/metrics"
 	"github.com/cina_dex_backend/internal/model"
 )
 
 // StateCache stores latest pool state and price in memory, updated by a background job.
+//
+// Values older than maxAge are treated as missing by the getters, so callers
+// fall through to a live read instead of serving stale data.
 type StateCache struct {
+	maxAge time.Duration
+
 	mu          sync.RWMutex
 	poolState   *model.PoolState
+	poolAt      time.Time
 	nativePrice *big.Int
+	priceInfo   *model.PriceInfo
+	priceAt     time.Time
+
+	subMu sync.Mutex
+	subs  map[chan struct{}]struct{}
+}
+
+// NewStateCache constructs a StateCache; maxAge <= 0 never expires values.
+func NewStateCache(maxAge time.Duration) *StateCache {
+	return &StateCache{
+		maxAge: maxAge,
+		subs:   make(map[chan struct{}]struct{}),
+	}
+}
+
+func (c *StateCache) expired(at time.Time) bool {
+	return c.maxAge > 0 && time.Since(at) > c.maxAge
+}
+
+// Subscribe returns a channel that is signalled after cache updates, and a
+// function to unsubscribe. Signals are coalesced: a slow subscriber sees at
+// least one signal after the latest update, not one per update.
+func (c *StateCache) Subscribe() (<-chan struct{}, func()) {
+	ch := make(chan struct{}, 1)
+
+	c.subMu.Lock()
+	c.subs[ch] = struct{}{}
+	c.subMu.Unlock()
+
+	return ch, func() {
+		c.subMu.Lock()
+		delete(c.subs, ch)
+		c.subMu.Unlock()
+	}
 }
 
-func NewStateCache() *StateCache {
-	return &StateCache{}
+func (c *StateCache) notify() {
+	c.subMu.Lock()
+	defer c.subMu.Unlock()
+	for ch := range c.subs {
+		select {
+		case ch <- struct{}{}:
+		default:
+		}
+	}
 }
 
 func (c *StateCache) SetPoolState(s *model.PoolState) {
 	c.mu.Lock()
-	defer c.mu.Unlock()
 	c.poolState = s
+	c.poolAt = time.Now()
+	c.mu.Unlock()
+	c.notify()
 }
 
 func (c *StateCache) GetPoolState() (*model.PoolState, bool) {
 	c.mu.RLock()
 	defer c.mu.RUnlock()
-	if c.poolState == nil {
+	ok := c.poolState != nil && !c.expired(c.poolAt)
+	metrics.StateCacheLookup("pool_state", ok)
+	if !ok {
 		return nil, false
 	}
 	return c.poolState, true
 }
 
+// PoolStateStatus reports the age of the cached pool state, including an
+// expired one.
+func (c *StateCache) PoolStateStatus() (*model.CacheStatus, bool) {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	if c.poolState == nil {
+		return nil, false
+	}
+	return c.status(c.poolAt, c.poolState.BlockNumber), true
+}
+
+// PriceStatus reports the age of the cached price, including an expired one.
+func (c *StateCache) PriceStatus() (*model.CacheStatus, bool) {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	if c.priceInfo == nil {
+		return nil, false
+	}
+	return c.status(c.priceAt, c.priceInfo.BlockNumber), true
+}
+
+func (c *StateCache) status(at time.Time, block uint64) *model.CacheStatus {
+	return &model.CacheStatus{
+		FetchedAt:   at.Unix(),
+		BlockNumber: block,
+		AgeSeconds:  int64(time.Since(at) / time.Second),
+		Expired:     c.expired(at),
+	}
+}
+
 func (c *StateCache) SetNativePrice(p *big.Int) {
+	defer c.notify()
 	c.mu.Lock()
 	defer c.mu.Unlock()
 	if p == nil {
@@ -42,14 +126,42 @@ func (c *StateCache) SetNativePrice(p *big.Int) {
 	}
 	// store a copy to avoid external mutation
 	c.nativePrice = new(big.Int).Set(p)
+	c.priceAt = time.Now()
 }
 
 func (c *StateCache) GetNativePrice() (*big.Int, bool) {
 	c.mu.RLock()
 	defer c.mu.RUnlock()
-	if c.nativePrice == nil {
+	if c.nativePrice == nil || c.expired(c.priceAt) {
 		return nil, false
 	}
 	// return a copy so callers cannot mutate internal state
 	return new(big.Int).Set(c.nativePrice), true
 }
+
+// SetPriceInfo stores the validated price and its check results; the native
+// price is updated from info.Price as well.
+func (c *StateCache) SetPriceInfo(info *model.PriceInfo) {
+	defer c.notify()
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.priceInfo = info
+	c.priceAt = time.Now()
+	if info == nil {
+		return
+	}
+	if p, ok := new(big.Int).SetString(info.Price, 10); ok {
+		c.nativePrice = p
+	}
+}
+
+func (c *StateCache) GetPriceInfo() (*model.PriceInfo, bool) {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	ok := c.priceInfo != nil && !c.expired(c.priceAt)
+	metrics.StateCacheLookup("price", ok)
+	if !ok {
+		return nil, false
+	}
+	return c.priceInfo, true
+}
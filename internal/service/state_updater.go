@@ -2,52 +2,253 @@ package service
 
 import (
 	"context"
-	"log"
+	"errors"
+	"log/slog"
+	"math/big"
+	"math/rand"
 	"time"
 
+	"github.com/cina_dex_backend/internal/config"
+	"github.com/cina_dex_backend/internal/health"
+	"github.com/cina_dex_backend/internal/lifecycle"
+	"github.com/cina_dex_backend/internal/metrics"
+	"github.com/cina_dex_backend/internal/model"
 	"github.com/cina_dex_backend/internal/onchain"
 )
 
-// StartStateUpdater launches a background goroutine that periodically refreshes
-// pool state and native price into the given cache.
-func StartStateUpdater(ctx context.Context, client onchain.Client, cache *StateCache, interval time.Duration) {
+// headResubscribeDelay is how long the updater polls for blocks after a head
+// subscription failed before subscribing again.
+const headResubscribeDelay = 30 * time.Second
+
+// StartStateUpdater launches a background goroutine that refreshes pool
+// state and the validated native price into the given cache.
+//
+// In RefreshInterval mode it refreshes every cfg.MaxInterval. In RefreshPoll
+// and RefreshSubscribe modes it refreshes when a new block arrives (oracle
+// updates land in blocks too), at most every cfg.MinInterval plus up to
+// cfg.Jitter, and at least every cfg.MaxInterval.
+func StartStateUpdater(ctx context.Context, workers *lifecycle.Workers, client onchain.Client, prices PriceService, cache *StateCache, cfg config.StateRefreshConfig) {
 	if cache == nil {
 		return
 	}
+	// serving stale state is worse than being taken out of rotation.
+	health.Register("state_updater", 3*cfg.MaxInterval, true)
+
+	var blocks <-chan uint64
+	switch cfg.Mode {
+	case config.RefreshPoll:
+		blocks = pollBlocks(ctx, workers, client, cfg.PollInterval)
+	case config.RefreshSubscribe:
+		blocks = subscribeBlocks(ctx, workers, client, cfg)
+	}
 
-	go func() {
+	workers.Go("state updater", func() {
 		// initial run
-		refreshOnce(ctx, client, cache)
+		refreshOnce(ctx, client, prices, cache)
+		last := time.Now()
 
-		ticker := time.NewTicker(interval)
-		defer ticker.Stop()
+		maxTimer := time.NewTimer(cfg.MaxInterval)
+		defer maxTimer.Stop()
+		// due fires a block-driven refresh; nil while none is scheduled.
+		var due <-chan time.Time
+
+		refresh := func() {
+			refreshOnce(ctx, client, prices, cache)
+			last = time.Now()
+			due = nil
+			if !maxTimer.Stop() {
+				select {
+				case <-maxTimer.C:
+				default:
+				}
+			}
+			maxTimer.Reset(cfg.MaxInterval)
+		}
 
 		for {
 			select {
 			case <-ctx.Done():
-				log.Println("state updater stopped: context cancelled")
+				slog.Info("state updater stopped: context cancelled")
+				return
+			case <-blocks:
+				if due != nil {
+					continue
+				}
+				delay := cfg.MinInterval - time.Since(last)
+				if delay < 0 {
+					delay = 0
+				}
+				if cfg.Jitter > 0 {
+					delay += time.Duration(rand.Int63n(int64(cfg.Jitter)))
+				}
+				due = time.After(delay)
+			case <-due:
+				refresh()
+			case <-maxTimer.C:
+				refresh()
+			}
+		}
+	})
+}
+
+// pollBlocks sends each new block number seen by polling eth_blockNumber.
+func pollBlocks(ctx context.Context, workers *lifecycle.Workers, client onchain.Client, interval time.Duration) <-chan uint64 {
+	out := make(chan uint64, 1)
+	workers.Go("block poller", func() { pollLoop(ctx, client, interval, out) })
+	return out
+}
+
+// pollLoop polls eth_blockNumber until ctx is done.
+func pollLoop(ctx context.Context, client onchain.Client, interval time.Duration, out chan uint64) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	var seen uint64
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+		n, err := client.BlockNumber(ctx)
+		if err != nil {
+			if ctx.Err() == nil {
+				slog.WarnContext(ctx, "state updater: poll block number", "err", err)
+			}
+			continue
+		}
+		if n > seen {
+			seen = n
+			sendBlock(out, n)
+		}
+	}
+}
+
+// subscribeBlocks sends new heads from the WebSocket RPC. While the
+// subscription is down it polls instead, retrying every headResubscribeDelay.
+func subscribeBlocks(ctx context.Context, workers *lifecycle.Workers, client onchain.Client, cfg config.StateRefreshConfig) <-chan uint64 {
+	out := make(chan uint64, 1)
+	workers.Go("head subscriber", func() {
+		for ctx.Err() == nil {
+			sub, err := onchain.SubscribeHeads(ctx, cfg.WSURL)
+			if err != nil {
+				slog.WarnContext(ctx, "state updater: head subscription failed, polling", "err", err, "retry_in", headResubscribeDelay)
+				pollCtx, cancel := context.WithTimeout(ctx, headResubscribeDelay)
+				pollLoop(pollCtx, client, cfg.PollInterval, out)
+				cancel()
+				continue
+			}
+			forwardHeads(ctx, sub.Heads(), out)
+			sub.Close()
+			if ctx.Err() == nil {
+				slog.InfoContext(ctx, "state updater: head subscription ended, resubscribing")
+			}
+			// avoid a hot loop when the endpoint drops every subscription.
+			select {
+			case <-ctx.Done():
+			case <-time.After(time.Second):
+			}
+		}
+	})
+	return out
+}
+
+func forwardHeads(ctx context.Context, heads <-chan *model.BlockRef, out chan uint64) {
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case h, ok := <-heads:
+			if !ok {
 				return
-			case <-ticker.C:
-				refreshOnce(ctx, client, cache)
 			}
+			sendBlock(out, h.Number)
 		}
-	}()
+	}
 }
 
-func refreshOnce(ctx context.Context, client onchain.Client, cache *StateCache) {
+// sendBlock signals a new block without blocking; the updater only needs to
+// know that at least one arrived.
+func sendBlock(out chan uint64, n uint64) {
+	select {
+	case out <- n:
+	default:
+	}
+}
+
+func refreshOnce(ctx context.Context, client onchain.Client, prices PriceService, cache *StateCache) {
 	if cache == nil {
 		return
 	}
 
-	if ps, err := client.GetPoolState(ctx); err != nil {
-		log.Printf("state updater: get pool state: %v", err)
+	// read the pool state at a known block so responses can report it.
+	block, err := client.BlockNumber(ctx)
+	if err != nil {
+		slog.WarnContext(ctx, "state updater: get block number", "err", err)
+	}
+
+	var ps *model.PoolState
+	if block > 0 {
+		ps, err = client.GetPoolStateAt(ctx, block)
+	} else {
+		ps, err = client.GetPoolState(ctx)
+	}
+	poolErr := err
+	if err != nil {
+		slog.WarnContext(ctx, "state updater: get pool state", "err", err)
 	} else {
+		ps.FetchedAt = time.Now().Unix()
+		ps.BlockNumber = block
 		cache.SetPoolState(ps)
+		recordPoolMetrics(ps)
 	}
 
-	if price, err := client.GetNativePrice(ctx); err != nil {
-		log.Printf("state updater: get native price: %v", err)
+	info, priceErr := prices.GetNativePrice(ctx)
+	if priceErr != nil {
+		slog.WarnContext(ctx, "state updater: get native price", "err", priceErr)
 	} else {
-		cache.SetNativePrice(price)
+		if info.Unsafe {
+			slog.WarnContext(ctx, "state updater: price flagged unsafe", "stale", info.Stale, "deviating", info.Deviating, "warnings", info.Warnings)
+		}
+		info.BlockNumber = block
+		cache.SetPriceInfo(info)
+		recordPriceMetrics(info)
+	}
+
+	jobDone("state_updater", errors.Join(poolErr, priceErr))
+}
+
+// recordPoolMetrics exports pool gauges in whole USDT.
+func recordPoolMetrics(ps *model.PoolState) {
+	assets := decimalFloat(ps.TotalAssets, 6)
+	borrowed := decimalFloat(ps.TotalBorrowed, 6)
+	metrics.PoolTotalAssets.Set(assets)
+	metrics.PoolTotalBorrowed.Set(borrowed)
+	metrics.PoolAvailableLiquidity.Set(decimalFloat(ps.AvailableLiquidity, 6))
+	utilization := 0.0
+	if assets > 0 {
+		utilization = borrowed / assets
+	}
+	metrics.PoolUtilization.Set(utilization)
+}
+
+func recordPriceMetrics(info *model.PriceInfo) {
+	metrics.NativePrice.Set(decimalFloat(info.Price, 18))
+	unsafe := 0.0
+	if info.Unsafe {
+		unsafe = 1
+	}
+	metrics.PriceUnsafe.Set(unsafe)
+}
+
+// decimalFloat converts an integer amount with the given decimals to a float
+// for gauges; invalid amounts read as 0.
+func decimalFloat(amount string, decimals int) float64 {
+	v, ok := new(big.Float).SetString(amount)
+	if !ok {
+		return 0
 	}
+	scale := new(big.Float).SetInt(new(big.Int).Exp(big.NewInt(10), big.NewInt(int64(decimals)), nil))
+	f, _ := v.Quo(v, scale).Float64()
+	return f
 }
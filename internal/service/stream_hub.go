@@ -0,0 +1,339 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"sort"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/cina_dex_backend/internal/lifecycle"
+	"github.com/cina_dex_backend/internal/model"
+	"github.com/cina_dex_backend/internal/onchain"
+)
+
+const (
+	// streamBufferSize is how many recent events are kept for resuming clients.
+	streamBufferSize = 1024
+	// streamClientBuffer is how many events may wait for a slow client before
+	// it is dropped; it can reconnect with Last-Event-ID.
+	streamClientBuffer = 64
+	// MaxStreamLoans bounds the loans a single client may watch.
+	MaxStreamLoans = 20
+)
+
+// ErrStreamLimit is returned by Subscribe when watching the requested loans
+// would exceed the hub's cap on distinct watched loans.
+var ErrStreamLimit = errors.New("stream watched loan limit reached")
+
+// StreamFilter selects the events a stream client receives.
+type StreamFilter struct {
+	Topics map[string]bool
+	// Loans are the loan ids whose health events are wanted.
+	Loans map[uint64]bool
+}
+
+func (f StreamFilter) match(ev *model.StreamEvent) bool {
+	if !f.Topics[ev.Type] {
+		return false
+	}
+	return ev.Type != model.StreamLoanHealth || f.Loans[ev.LoanID]
+}
+
+// StreamClient receives events matching its filter.
+type StreamClient struct {
+	filter StreamFilter
+	events chan *model.StreamEvent
+	// dropped is closed when the client fell too far behind or the hub
+	// closed; shutdown tells the two apart.
+	dropped  chan struct{}
+	shutdown bool
+}
+
+// Events delivers matching events in id order.
+func (c *StreamClient) Events() <-chan *model.StreamEvent { return c.events }
+
+// Dropped is closed when the hub gave up on a slow client or closed.
+func (c *StreamClient) Dropped() <-chan struct{} { return c.dropped }
+
+// Shutdown reports whether the client was dropped because the hub closed;
+// it is only meaningful once Dropped is closed.
+func (c *StreamClient) Shutdown() bool { return c.shutdown }
+
+// StreamHub fans pool, price and loan health events out to stream clients.
+// Recent events are buffered so clients can resume from a Last-Event-ID.
+type StreamHub struct {
+	mu      sync.Mutex
+	seq     uint64
+	buf     []*model.StreamEvent // ring of the last streamBufferSize events
+	head    int
+	clients map[*StreamClient]struct{}
+	// latest holds the newest event per pool / price / loan, for snapshots.
+	latest  map[string]*model.StreamEvent
+	watched map[uint64]int
+	// maxWatched caps len(watched).
+	maxWatched int
+	// watchCh signals the publisher that new loans are watched.
+	watchCh chan struct{}
+	// closed is set on shutdown; later subscribers are dropped at once.
+	closed bool
+}
+
+// NewStreamHub constructs an empty StreamHub watching at most maxWatched
+// distinct loans. Event ids start from the current unix time in
+// milliseconds * 1000, so they keep increasing across restarts yet stay
+// within JavaScript's safe integer range.
+func NewStreamHub(maxWatched int) *StreamHub {
+	return &StreamHub{
+		seq:        uint64(time.Now().UnixMilli()) * 1000,
+		clients:    make(map[*StreamClient]struct{}),
+		latest:     make(map[string]*model.StreamEvent),
+		watched:    make(map[uint64]int),
+		maxWatched: maxWatched,
+		watchCh:    make(chan struct{}, 1),
+	}
+}
+
+func streamKey(typ string, loanID uint64) string {
+	if typ == model.StreamLoanHealth {
+		return typ + ":" + strconv.FormatUint(loanID, 10)
+	}
+	return typ
+}
+
+// Publish assigns the next id to an event and delivers it.
+func (h *StreamHub) Publish(typ string, loanID uint64, data interface{}) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	h.seq++
+	ev := &model.StreamEvent{
+		ID:     h.seq,
+		Type:   typ,
+		LoanID: loanID,
+		Time:   time.Now().Unix(),
+		Data:   data,
+	}
+	h.latest[streamKey(typ, loanID)] = ev
+
+	if len(h.buf) < streamBufferSize {
+		h.buf = append(h.buf, ev)
+	} else {
+		h.buf[h.head] = ev
+		h.head = (h.head + 1) % streamBufferSize
+	}
+
+	for c := range h.clients {
+		if !c.filter.match(ev) {
+			continue
+		}
+		select {
+		case c.events <- ev:
+		default:
+			h.drop(c)
+		}
+	}
+}
+
+// Latest returns the newest event of a type, if any.
+func (h *StreamHub) Latest(typ string, loanID uint64) (*model.StreamEvent, bool) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	ev, ok := h.latest[streamKey(typ, loanID)]
+	return ev, ok
+}
+
+// Subscribe registers a client and returns the events it should receive
+// before live ones. With resume set, these are the buffered events after
+// lastEventID; if that id is no longer buffered (or resume is false), they
+// are the latest state of each matching topic. It returns ErrStreamLimit
+// when the filter's loans would exceed the watched loan cap.
+func (h *StreamHub) Subscribe(filter StreamFilter, lastEventID uint64, resume bool) (*StreamClient, []*model.StreamEvent, error) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	c := &StreamClient{
+		filter:  filter,
+		events:  make(chan *model.StreamEvent, streamClientBuffer),
+		dropped: make(chan struct{}),
+	}
+	if h.closed {
+		c.shutdown = true
+		close(c.dropped)
+		return c, nil, nil
+	}
+
+	newLoans := 0
+	for id := range filter.Loans {
+		if h.watched[id] == 0 {
+			newLoans++
+		}
+	}
+	if len(h.watched)+newLoans > h.maxWatched {
+		return nil, nil, fmt.Errorf("%w: %d loans", ErrStreamLimit, h.maxWatched)
+	}
+	h.clients[c] = struct{}{}
+	for id := range filter.Loans {
+		h.watched[id]++
+	}
+	if newLoans > 0 {
+		select {
+		case h.watchCh <- struct{}{}:
+		default:
+		}
+	}
+
+	if resume && len(h.buf) > 0 && lastEventID+1 >= h.oldest().ID {
+		var backlog []*model.StreamEvent
+		for i := 0; i < len(h.buf); i++ {
+			ev := h.buf[(h.head+i)%len(h.buf)]
+			if ev.ID > lastEventID && filter.match(ev) {
+				backlog = append(backlog, ev)
+			}
+		}
+		return c, backlog, nil
+	}
+
+	snapshot := make([]*model.StreamEvent, 0, len(h.latest))
+	for _, ev := range h.latest {
+		if filter.match(ev) {
+			snapshot = append(snapshot, ev)
+		}
+	}
+	sort.Slice(snapshot, func(i, j int) bool { return snapshot[i].ID < snapshot[j].ID })
+	return c, snapshot, nil
+}
+
+func (h *StreamHub) oldest() *model.StreamEvent {
+	return h.buf[h.head%len(h.buf)]
+}
+
+// Unsubscribe removes a client.
+func (h *StreamHub) Unsubscribe(c *StreamClient) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.drop(c)
+}
+
+// Close drops every client so open streams end during shutdown.
+func (h *StreamHub) Close() {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.closed = true
+	for c := range h.clients {
+		c.shutdown = true
+		h.drop(c)
+	}
+}
+
+// drop removes c and releases its watched loans; callers hold mu.
+func (h *StreamHub) drop(c *StreamClient) {
+	if _, ok := h.clients[c]; !ok {
+		return
+	}
+	delete(h.clients, c)
+	close(c.dropped)
+	for id := range c.filter.Loans {
+		if h.watched[id]--; h.watched[id] <= 0 {
+			delete(h.watched, id)
+			delete(h.latest, streamKey(model.StreamLoanHealth, id))
+		}
+	}
+}
+
+// WatchedLoans returns the loan ids at least one client watches.
+func (h *StreamHub) WatchedLoans() []uint64 {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	ids := make([]uint64, 0, len(h.watched))
+	for id := range h.watched {
+		ids = append(ids, id)
+	}
+	return ids
+}
+
+// StartStreamPublisher publishes pool state and price changes after every
+// cache update, and re-reads getLoanHealth of watched loans, publishing
+// those whose health changed. Newly watched loans are read immediately.
+func StartStreamPublisher(ctx context.Context, workers *lifecycle.Workers, hub *StreamHub, cache *StateCache, client onchain.Client) {
+	if hub == nil || cache == nil {
+		return
+	}
+	updates, unsubscribe := cache.Subscribe()
+
+	workers.Go("stream publisher", func() {
+		defer unsubscribe()
+
+		for {
+			select {
+			case <-ctx.Done():
+				slog.Info("stream publisher stopped: context cancelled")
+				return
+			case <-updates:
+				publishState(hub, cache)
+				publishLoanHealth(ctx, hub, client, false)
+			case <-hub.watchCh:
+				publishLoanHealth(ctx, hub, client, true)
+			}
+		}
+	})
+}
+
+func publishState(hub *StreamHub, cache *StateCache) {
+	if ps, ok := cache.GetPoolState(); ok {
+		last, seen := hub.Latest(model.StreamPool, 0)
+		if !seen || !samePoolState(last.Data.(*model.PoolState), ps) {
+			hub.Publish(model.StreamPool, 0, ps)
+		}
+	}
+	if info, ok := cache.GetPriceInfo(); ok {
+		last, seen := hub.Latest(model.StreamPrice, 0)
+		if !seen {
+			hub.Publish(model.StreamPrice, 0, info)
+			return
+		}
+		prev := last.Data.(*model.PriceInfo)
+		if prev.Price != info.Price || prev.Unsafe != info.Unsafe {
+			hub.Publish(model.StreamPrice, 0, info)
+		}
+	}
+}
+
+// samePoolState compares pool values, ignoring when they were read.
+func samePoolState(a, b *model.PoolState) bool {
+	x, y := *a, *b
+	x.FetchedAt, x.BlockNumber = 0, 0
+	y.FetchedAt, y.BlockNumber = 0, 0
+	return x == y
+}
+
+// publishLoanHealth reads watched loans; with onlyNew it skips loans that
+// already have a published health.
+func publishLoanHealth(ctx context.Context, hub *StreamHub, client onchain.Client, onlyNew bool) {
+	for _, id := range hub.WatchedLoans() {
+		last, seen := hub.Latest(model.StreamLoanHealth, id)
+		if onlyNew && seen {
+			continue
+		}
+		health, err := client.GetLoanHealth(ctx, id)
+		if err != nil {
+			if ctx.Err() != nil {
+				return
+			}
+			slog.WarnContext(ctx, "stream publisher: get loan health", "loan_id", id, "err", err)
+			continue
+		}
+		update := &model.LoanHealthUpdate{
+			LoanID:         id,
+			LTV:            health.LTV,
+			IsLiquidatable: health.IsLiquidatable,
+		}
+		if seen && *last.Data.(*model.LoanHealthUpdate) == *update {
+			continue
+		}
+		hub.Publish(model.StreamLoanHealth, id, update)
+	}
+}
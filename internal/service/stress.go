@@ -0,0 +1,202 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math"
+	"math/big"
+	"time"
+
+	"github.com/cina_dex_backend/internal/model"
+	"github.com/cina_dex_backend/internal/tracing"
+)
+
+// maxStressScenarios bounds the prices evaluated in one stress test.
+const maxStressScenarios = 20
+
+// ErrInvalidStressScenario is returned for malformed or out-of-range scenarios.
+var ErrInvalidStressScenario = errors.New("invalid stress scenario")
+
+// StressService revalues all active loans at hypothetical BNB/USD prices.
+type StressService interface {
+	// Run evaluates each absolute price (18 decimals) and each percentage
+	// change from the current price (e.g. -30 for a 30% drop).
+	Run(ctx context.Context, prices []string, changes []float64) (*model.StressTest, error)
+}
+
+type stressService struct {
+	loans  *LoanScanner
+	pool   PoolService
+	prices PriceService
+	cache  *StateCache
+}
+
+// NewStressService constructs a StressService. The current price is taken
+// from the cache when available and read through prices otherwise.
+func NewStressService(loans *LoanScanner, pool PoolService, prices PriceService, cache *StateCache) StressService {
+	return &stressService{
+		loans:  loans,
+		pool:   pool,
+		prices: prices,
+		cache:  cache,
+	}
+}
+
+// stressLoan is an active loan with parsed amounts.
+type stressLoan struct {
+	id         uint64
+	debt       *big.Int
+	collateral *big.Int
+}
+
+// Run applies the same LTV math as getLoanHealth / the quote service to every
+// active loan at each price. Loans whose collateral no longer covers the debt
+// are assumed not to be liquidated and count as bad debt instead.
+func (s *stressService) Run(ctx context.Context, prices []string, changes []float64) (_ *model.StressTest, err error) {
+	ctx, span := tracing.Start(ctx, "StressService.Run")
+	defer func() { tracing.End(span, err) }()
+
+	if n := len(prices) + len(changes); n == 0 || n > maxStressScenarios {
+		return nil, fmt.Errorf("%w: between 1 and %d scenarios required", ErrInvalidStressScenario, maxStressScenarios)
+	}
+
+	// validate input before any RPC.
+	targets := make([]*big.Int, 0, len(prices)+len(changes))
+	for _, raw := range prices {
+		p, err := parseBig(raw)
+		if err != nil || p.Sign() <= 0 {
+			return nil, fmt.Errorf("%w: price %q", ErrInvalidStressScenario, raw)
+		}
+		targets = append(targets, p)
+	}
+	for _, pct := range changes {
+		if math.IsNaN(pct) || pct <= -100 || pct > 1000 {
+			return nil, fmt.Errorf("%w: change %v%% out of range (-100, 1000]", ErrInvalidStressScenario, pct)
+		}
+	}
+
+	info, current, err := validatedPrice(ctx, s.cache, s.prices)
+	if err != nil {
+		return nil, err
+	}
+	for _, pct := range changes {
+		// price = current * (10000 + bps) / 10000
+		bps := big.NewInt(int64(math.Round(pct * 100)))
+		p := new(big.Int).Mul(current, bps.Add(bps, bpsDenom))
+		p.Quo(p, bpsDenom)
+		if p.Sign() <= 0 {
+			return nil, fmt.Errorf("%w: change %v%% yields a zero price", ErrInvalidStressScenario, pct)
+		}
+		targets = append(targets, p)
+	}
+
+	ps, err := s.pool.GetPoolState(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("get pool state: %w", err)
+	}
+	assets, err := parseBig(ps.TotalAssets)
+	if err != nil {
+		return nil, fmt.Errorf("invalid totalAssets: %w", err)
+	}
+	active, err := s.loans.ActiveLoans(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("scan loans: %w", err)
+	}
+
+	out := &model.StressTest{
+		CheckedAt:    time.Now().Unix(),
+		CurrentPrice: current.String(),
+		ActiveLoans:  len(active),
+		TotalAssets:  assets.String(),
+		Scenarios:    make([]*model.StressScenario, 0, len(targets)),
+	}
+	if info.Unsafe {
+		out.Warnings = append(out.Warnings, "current price is flagged unsafe; percentage scenarios are relative to it")
+	}
+
+	loans := make([]stressLoan, 0, len(active))
+	totalDebt := new(big.Int)
+	for _, loan := range active {
+		debt, err1 := parseBig(loan.RepaymentAmount)
+		collateral, err2 := parseBig(loan.CollateralAmount)
+		if err1 != nil || err2 != nil {
+			out.Warnings = append(out.Warnings, fmt.Sprintf("loan %d: invalid amounts", loan.ID))
+			continue
+		}
+		totalDebt.Add(totalDebt, debt)
+		loans = append(loans, stressLoan{id: loan.ID, debt: debt, collateral: collateral})
+	}
+	out.TotalDebt = totalDebt.String()
+
+	liquidatableNow := make(map[uint64]bool, len(loans))
+	for _, l := range loans {
+		if isLiquidatableLTV(computeLTV(l.debt, l.collateral, current)) {
+			liquidatableNow[l.id] = true
+		}
+	}
+
+	out.Current = stressScenario(loans, current, current, assets, liquidatableNow)
+	for _, p := range targets {
+		out.Scenarios = append(out.Scenarios, stressScenario(loans, p, current, assets, liquidatableNow))
+	}
+	return out, nil
+}
+
+// stressScenario values loans at price.
+func stressScenario(loans []stressLoan, price, current, assets *big.Int, liquidatableNow map[uint64]bool) *model.StressScenario {
+	var (
+		debtAtRisk = new(big.Int)
+		collAtRisk = new(big.Int)
+		payout     = new(big.Int)
+		payoutUSDT = new(big.Int)
+		profit     = new(big.Int)
+		badDebt    = new(big.Int)
+	)
+	sc := &model.StressScenario{
+		Price:         price.String(),
+		ChangePercent: new(big.Rat).SetFrac(new(big.Int).Mul(new(big.Int).Sub(price, current), hundred), current).FloatString(2),
+	}
+
+	for _, l := range loans {
+		value := usdToUSDT(collateralValueUSD(l.collateral, price))
+		if gap := new(big.Int).Sub(l.debt, value); gap.Sign() > 0 {
+			sc.BadDebtLoans++
+			badDebt.Add(badDebt, gap)
+		}
+
+		if !isLiquidatableLTV(computeLTV(l.debt, l.collateral, price)) {
+			continue
+		}
+		sc.LiquidatableLoans++
+		if !liquidatableNow[l.id] {
+			sc.NewlyLiquidatable++
+		}
+		debtAtRisk.Add(debtAtRisk, l.debt)
+		collAtRisk.Add(collAtRisk, l.collateral)
+
+		if value.Cmp(l.debt) < 0 {
+			continue
+		}
+		seized := liquidationSeizure(l.debt, l.collateral, price)
+		seizedUSDT := usdToUSDT(collateralValueUSD(seized, price))
+		payout.Add(payout, seized)
+		payoutUSDT.Add(payoutUSDT, seizedUSDT)
+		profit.Add(profit, seizedUSDT.Sub(seizedUSDT, l.debt))
+	}
+
+	shortfallBps := new(big.Int)
+	if assets.Sign() > 0 {
+		shortfallBps.Mul(badDebt, bpsDenom)
+		shortfallBps.Quo(shortfallBps, assets)
+	}
+
+	sc.DebtAtRisk = debtAtRisk.String()
+	sc.CollateralAtRisk = collAtRisk.String()
+	sc.LiquidatorPayout = payout.String()
+	sc.LiquidatorPayoutValue = payoutUSDT.String()
+	sc.LiquidatorProfit = profit.String()
+	sc.BadDebt = badDebt.String()
+	sc.ShortfallBps = shortfallBps.String()
+	return sc
+}
@@ -9,6 +9,7 @@ import (
 	"github.com/cina_dex_backend/internal/config"
 	"github.com/cina_dex_backend/internal/model"
 	"github.com/cina_dex_backend/internal/onchain"
+	"github.com/cina_dex_backend/internal/tracing"
 	"github.com/ethereum/go-ethereum/common"
 )
 
@@ -24,15 +25,37 @@ type TxService interface {
 	BuildWithdrawTx(ctx context.Context, fTokenAmount string) (*model.WithdrawTx, error)
 	// BuildMintMockUSDTTx builds a single mint(to, amount) call for MockUSDT on testnet.
 	// It is intended for frontend faucets where the owner wallet signs the tx.
+	//
+	// Deprecated: it requires the owner key in the browser; use FaucetService,
+	// which signs server-side.
 	BuildMintMockUSDTTx(ctx context.Context, to, amount string) (*model.TxCall, error)
+	// BuildRepayBatchTx builds one approve for the total repayment plus a repay
+	// call per loan. Every loan must be active and borrowed by sender.
+	BuildRepayBatchTx(ctx context.Context, sender string, loanIDs []uint64, wrap string) (*model.BatchTx, error)
+	// BuildLiquidateBatchTx builds one approve for the total debt plus a
+	// liquidate call per loan. Every loan must be active and liquidatable.
+	BuildLiquidateBatchTx(ctx context.Context, sender string, loanIDs []uint64, wrap string) (*model.BatchTx, error)
 }
 
+// Batch wrapping modes accepted by the batch builders.
+const (
+	// BatchWrapNone returns approve + calls for wallets that send them one by one
+	// (or batch them client side, e.g. EIP-5792 wallet_sendCalls).
+	BatchWrapNone = ""
+	// BatchWrapSafe additionally encodes all calls as one Safe MultiSend tx.
+	BatchWrapSafe = "safe"
+)
+
+// maxBatchLoans bounds how many loans a single batch may touch.
+const maxBatchLoans = 50
+
 // txService is the default implementation of TxService.
 type txService struct {
 	cfg       *config.Config
 	client    onchain.Client
 	tokenAddr common.Address
 	poolAddr  common.Address
+	multiSend common.Address
 }
 
 // Precomputed selectors for write methods.
@@ -44,6 +67,7 @@ var (
 	selectorRepay        = []byte{0x37, 0x1f, 0xd8, 0xe6} // repay(uint256)
 	selectorLiquidate    = []byte{0x41, 0x5f, 0x12, 0x40} // liquidate(uint256)
 	selectorMockUSDTMint = []byte{0x40, 0xc1, 0x0f, 0x19} // mint(address,uint256)
+	selectorMultiSend    = []byte{0x8d, 0x80, 0xff, 0x0a} // multiSend(bytes)
 )
 
 // NewTxService constructs a TxService; it infers the USDT/MockUSDT address
@@ -60,12 +84,16 @@ func NewTxService(cfg *config.Config, c onchain.Client) (TxService, error) {
 		return nil, fmt.Errorf("invalid lendingPool address in chain config: %s", cfg.ChainConfig.LendingPool)
 	}
 
-	return &txService{
+	svc := &txService{
 		cfg:       cfg,
 		client:    c,
 		tokenAddr: common.HexToAddress(token),
 		poolAddr:  common.HexToAddress(cfg.ChainConfig.LendingPool),
-	}, nil
+	}
+	if isHexAddress(cfg.SafeMultiSend) {
+		svc.multiSend = common.HexToAddress(cfg.SafeMultiSend)
+	}
+	return svc, nil
 }
 
 // BuildDepositTx builds approve + deposit calls given an amount of USDT.
@@ -153,7 +181,10 @@ func (s *txService) BuildWithdrawTx(ctx context.Context, fTokenAmount string) (*
 
 // BuildRepayTx builds approve + repay for a given loanId, using on-chain
 // repaymentAmount from loans(loanId).
-func (s *txService) BuildRepayTx(ctx context.Context, loanID uint64) (*model.RepayTx, error) {
+func (s *txService) BuildRepayTx(ctx context.Context, loanID uint64) (_ *model.RepayTx, err error) {
+	ctx, span := tracing.Start(ctx, "TxService.BuildRepayTx", tracing.LoanID(loanID))
+	defer func() { tracing.End(span, err) }()
+
 	loan, err := s.client.GetLoan(ctx, loanID)
 	if err != nil {
 		return nil, fmt.Errorf("read loan: %w", err)
@@ -166,26 +197,18 @@ func (s *txService) BuildRepayTx(ctx context.Context, loanID uint64) (*model.Rep
 
 	approve := buildApproveCall(s.tokenAddr, s.poolAddr, repAmount)
 
-	// repay(uint256 loanId)
-	data := make([]byte, len(selectorRepay)+32)
-	copy(data, selectorRepay)
-	copy(data[len(selectorRepay):], packUint64(loanID))
-
-	repay := &model.TxCall{
-		To:    s.poolAddr.Hex(),
-		Data:  bytesToHex(data),
-		Value: "0",
-	}
-
 	return &model.RepayTx{
 		Approve: approve,
-		Repay:   repay,
+		Repay:   s.buildLoanCall(selectorRepay, loanID),
 	}, nil
 }
 
 // BuildLiquidateTx builds approve + liquidate for a given loanId, using the
 // current repaymentAmount on-chain as the amount the liquidator needs to pay.
-func (s *txService) BuildLiquidateTx(ctx context.Context, loanID uint64) (*model.LiquidateTx, error) {
+func (s *txService) BuildLiquidateTx(ctx context.Context, loanID uint64) (_ *model.LiquidateTx, err error) {
+	ctx, span := tracing.Start(ctx, "TxService.BuildLiquidateTx", tracing.LoanID(loanID))
+	defer func() { tracing.End(span, err) }()
+
 	loan, err := s.client.GetLoan(ctx, loanID)
 	if err != nil {
 		return nil, fmt.Errorf("read loan: %w", err)
@@ -198,20 +221,9 @@ func (s *txService) BuildLiquidateTx(ctx context.Context, loanID uint64) (*model
 
 	approve := buildApproveCall(s.tokenAddr, s.poolAddr, repAmount)
 
-	// liquidate(uint256 loanId)
-	data := make([]byte, len(selectorLiquidate)+32)
-	copy(data, selectorLiquidate)
-	copy(data[len(selectorLiquidate):], packUint64(loanID))
-
-	liq := &model.TxCall{
-		To:    s.poolAddr.Hex(),
-		Data:  bytesToHex(data),
-		Value: "0",
-	}
-
 	return &model.LiquidateTx{
 		Approve:   approve,
-		Liquidate: liq,
+		Liquidate: s.buildLoanCall(selectorLiquidate, loanID),
 	}, nil
 }
 
@@ -219,6 +231,8 @@ func (s *txService) BuildLiquidateTx(ctx context.Context, loanID uint64) (*model
 // - to: recipient address (0x...)
 // - amount: decimal string in the token's smallest unit (6 decimals for MockUSDT/USDT).
 // This is intended for testnet faucets where the owner wallet signs and sends the tx.
+//
+// Deprecated: use FaucetService.Claim.
 func (s *txService) BuildMintMockUSDTTx(ctx context.Context, to, amount string) (*model.TxCall, error) {
 	// Guard: only allow when a MockUSDT address is configured (i.e. testnet).
 	if s.cfg.ChainConfig.MockUSDT == "" {
@@ -246,6 +260,153 @@ func (s *txService) BuildMintMockUSDTTx(ctx context.Context, to, amount string)
 	}, nil
 }
 
+// BuildRepayBatchTx builds a "repay all" flow: a single approve for the sum of
+// repaymentAmount over all loans, followed by repay(loanId) for each loan in
+// the given order.
+func (s *txService) BuildRepayBatchTx(ctx context.Context, sender string, loanIDs []uint64, wrap string) (_ *model.BatchTx, err error) {
+	ctx, span := tracing.Start(ctx, "TxService.BuildRepayBatchTx", tracing.Address(sender), tracing.LoanCount(len(loanIDs)))
+	defer func() { tracing.End(span, err) }()
+
+	if !isHexAddress(sender) {
+		return nil, fmt.Errorf("invalid sender address: %s", sender)
+	}
+	senderAddr := common.HexToAddress(sender)
+
+	return s.buildBatch(ctx, loanIDs, wrap, selectorRepay, func(loan *model.Loan) error {
+		if common.HexToAddress(loan.Borrower) != senderAddr {
+			return fmt.Errorf("loan %d is not owned by %s", loan.ID, senderAddr.Hex())
+		}
+		return nil
+	})
+}
+
+// BuildLiquidateBatchTx builds a "liquidate many" flow: a single approve for
+// the sum of repaymentAmount over all loans, followed by liquidate(loanId) for
+// each loan. Loans that are not liquidatable per getLoanHealth are rejected.
+func (s *txService) BuildLiquidateBatchTx(ctx context.Context, sender string, loanIDs []uint64, wrap string) (_ *model.BatchTx, err error) {
+	ctx, span := tracing.Start(ctx, "TxService.BuildLiquidateBatchTx", tracing.Address(sender), tracing.LoanCount(len(loanIDs)))
+	defer func() { tracing.End(span, err) }()
+
+	if sender != "" && !isHexAddress(sender) {
+		return nil, fmt.Errorf("invalid sender address: %s", sender)
+	}
+
+	return s.buildBatch(ctx, loanIDs, wrap, selectorLiquidate, func(loan *model.Loan) error {
+		health, err := s.client.GetLoanHealth(ctx, loan.ID)
+		if err != nil {
+			return fmt.Errorf("read loan %d health: %w", loan.ID, err)
+		}
+		if !health.IsLiquidatable {
+			return fmt.Errorf("loan %d is not liquidatable", loan.ID)
+		}
+		return nil
+	})
+}
+
+// buildBatch reads and validates every loan, then assembles the aggregated
+// approve and the per-loan calls encoded with selector(loanId).
+func (s *txService) buildBatch(ctx context.Context, loanIDs []uint64, wrap string, selector []byte, validate func(*model.Loan) error) (*model.BatchTx, error) {
+	if len(loanIDs) == 0 {
+		return nil, fmt.Errorf("at least one loanId is required")
+	}
+	if len(loanIDs) > maxBatchLoans {
+		return nil, fmt.Errorf("too many loans in batch: %d (max %d)", len(loanIDs), maxBatchLoans)
+	}
+	if wrap != BatchWrapNone && wrap != BatchWrapSafe {
+		return nil, fmt.Errorf("unsupported wrap %q", wrap)
+	}
+
+	total := new(big.Int)
+	calls := make([]*model.TxCall, 0, len(loanIDs))
+	seen := make(map[uint64]bool, len(loanIDs))
+	for _, id := range loanIDs {
+		if seen[id] {
+			return nil, fmt.Errorf("duplicate loanId %d", id)
+		}
+		seen[id] = true
+
+		loan, err := s.client.GetLoan(ctx, id)
+		if err != nil {
+			return nil, fmt.Errorf("read loan %d: %w", id, err)
+		}
+		if !loan.IsActive {
+			return nil, fmt.Errorf("loan %d is not active", id)
+		}
+		if err := validate(loan); err != nil {
+			return nil, err
+		}
+
+		repAmount, err := parseBig(loan.RepaymentAmount)
+		if err != nil {
+			return nil, fmt.Errorf("invalid repaymentAmount on-chain for loan %d: %w", id, err)
+		}
+		total.Add(total, repAmount)
+		calls = append(calls, s.buildLoanCall(selector, id))
+	}
+
+	batch := &model.BatchTx{
+		LoanIDs:     loanIDs,
+		TotalAmount: total.String(),
+		Approve:     buildApproveCall(s.tokenAddr, s.poolAddr, total),
+		Calls:       calls,
+	}
+
+	if wrap == BatchWrapSafe {
+		if (s.multiSend == common.Address{}) {
+			return nil, fmt.Errorf("safe multiSend address not configured")
+		}
+		batch.Safe = buildMultiSendTx(s.multiSend, append([]*model.TxCall{batch.Approve}, calls...))
+	}
+
+	return batch, nil
+}
+
+// buildLoanCall encodes a LendingPool call that takes a single loanId,
+// e.g. repay(uint256) or liquidate(uint256).
+func (s *txService) buildLoanCall(selector []byte, loanID uint64) *model.TxCall {
+	data := make([]byte, len(selector)+32)
+	copy(data, selector)
+	copy(data[len(selector):], packUint64(loanID))
+
+	return &model.TxCall{
+		To:    s.poolAddr.Hex(),
+		Data:  bytesToHex(data),
+		Value: "0",
+	}
+}
+
+// buildMultiSendTx encodes calls for Safe's multiSend(bytes). Each call is
+// packed as operation(uint8=0 CALL) | to(20) | value(32) | dataLength(32) | data.
+func buildMultiSendTx(multiSend common.Address, calls []*model.TxCall) *model.SafeTx {
+	var packed []byte
+	for _, call := range calls {
+		// calls are built by this service, so fields are always well-formed
+		value, _ := parseBig(call.Value)
+		data := common.FromHex(call.Data)
+
+		packed = append(packed, 0)
+		packed = append(packed, common.HexToAddress(call.To).Bytes()...)
+		packed = append(packed, packUint256(value)...)
+		packed = append(packed, packUint64(uint64(len(data)))...)
+		packed = append(packed, data...)
+	}
+
+	// multiSend(bytes transactions): offset, length, right-padded bytes
+	padded := (len(packed) + 31) / 32 * 32
+	data := make([]byte, len(selectorMultiSend)+64+padded)
+	copy(data, selectorMultiSend)
+	copy(data[len(selectorMultiSend):], packUint64(32))
+	copy(data[len(selectorMultiSend)+32:], packUint64(uint64(len(packed))))
+	copy(data[len(selectorMultiSend)+64:], packed)
+
+	return &model.SafeTx{
+		To:        multiSend.Hex(),
+		Data:      bytesToHex(data),
+		Value:     "0",
+		Operation: 1,
+	}
+}
+
 // buildApproveCall creates an ERC20 approve(spender, amount) TxCall.
 func buildApproveCall(token, spender common.Address, amt *big.Int) *model.TxCall {
 	data := make([]byte, len(selectorERC20Approve)+64)
@@ -0,0 +1,112 @@
+package service
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/cina_dex_backend/internal/model"
+	"github.com/cina_dex_backend/internal/onchain"
+	"github.com/ethereum/go-ethereum/accounts/abi"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/crypto"
+)
+
+func TestBuildMultiSendTx(t *testing.T) {
+	multiSend := common.HexToAddress("0x40A2aCCbd92BCA938b02010E17A5b8929b49130D")
+	calls := []*model.TxCall{
+		{To: "0x1111111111111111111111111111111111111111", Data: "0xdeadbeef", Value: "0"},
+		{To: "0x2222222222222222222222222222222222222222", Data: "0x", Value: "1"},
+	}
+
+	tx := buildMultiSendTx(multiSend, calls)
+	if tx.To != multiSend.Hex() || tx.Value != "0" || tx.Operation != 1 {
+		t.Fatalf("safe tx = %+v, want a DELEGATECALL to %s", tx, multiSend.Hex())
+	}
+
+	word := func(v string) string { return strings.Repeat("0", 64-len(v)) + v }
+	packed := "00" + strings.Repeat("11", 20) + word("0") + word("4") + "deadbeef" +
+		"00" + strings.Repeat("22", 20) + word("1") + word("0")
+	// 89 + 85 = 174 (0xae) bytes, right-padded to 192
+	want := "0x8d80ff0a" + word("20") + word("ae") + packed + strings.Repeat("0", 2*(192-174))
+	if tx.Data != want {
+		t.Fatalf("data =\n%s\nwant\n%s", tx.Data, want)
+	}
+
+	// the calldata must also decode as multiSend(bytes) with the standard ABI.
+	data := common.FromHex(tx.Data)
+	if sel := crypto.Keccak256([]byte("multiSend(bytes)"))[:4]; string(data[:4]) != string(sel) {
+		t.Fatalf("selector = %x, want %x", data[:4], sel)
+	}
+	bytesType, _ := abi.NewType("bytes", "", nil)
+	args, err := abi.Arguments{{Type: bytesType}}.Unpack(data[4:])
+	if err != nil {
+		t.Fatalf("unpack multiSend(bytes): %v", err)
+	}
+	if got := common.Bytes2Hex(args[0].([]byte)); got != packed {
+		t.Fatalf("transactions =\n%s\nwant\n%s", got, packed)
+	}
+}
+
+// batchChain serves active loans owned by borrower.
+type batchChain struct {
+	onchain.Client
+	borrower string
+	reads    int
+}
+
+func (c *batchChain) GetLoan(_ context.Context, id uint64) (*model.Loan, error) {
+	c.reads++
+	return &model.Loan{ID: id, Borrower: c.borrower, RepaymentAmount: "100", IsActive: true}, nil
+}
+
+func TestBuildRepayBatchTxLimits(t *testing.T) {
+	const borrower = "0x3333333333333333333333333333333333333333"
+	ids := func(n int) []uint64 {
+		out := make([]uint64, n)
+		for i := range out {
+			out[i] = uint64(i + 1)
+		}
+		return out
+	}
+
+	tests := []struct {
+		name    string
+		loanIDs []uint64
+		wantErr string
+		// maxReads bounds the loans read before rejecting.
+		maxReads int
+	}{
+		{name: "empty", loanIDs: nil, wantErr: "at least one loanId"},
+		{name: "duplicate", loanIDs: []uint64{1, 2, 1}, wantErr: "duplicate loanId 1", maxReads: 2},
+		{name: "too many", loanIDs: ids(maxBatchLoans + 1), wantErr: "too many loans in batch: 51 (max 50)"},
+		{name: "max batch", loanIDs: ids(maxBatchLoans), maxReads: maxBatchLoans},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			chain := &batchChain{borrower: borrower}
+			s := &txService{
+				client:    chain,
+				tokenAddr: common.HexToAddress("0x1111111111111111111111111111111111111111"),
+				poolAddr:  common.HexToAddress("0x2222222222222222222222222222222222222222"),
+			}
+
+			batch, err := s.BuildRepayBatchTx(context.Background(), borrower, tt.loanIDs, BatchWrapNone)
+			if chain.reads > tt.maxReads {
+				t.Errorf("read %d loans, want at most %d", chain.reads, tt.maxReads)
+			}
+			if tt.wantErr == "" {
+				if err != nil {
+					t.Fatalf("unexpected error: %v", err)
+				}
+				if len(batch.Calls) != len(tt.loanIDs) || batch.TotalAmount != "5000" {
+					t.Fatalf("batch has %d calls for %s, want %d for 5000", len(batch.Calls), batch.TotalAmount, len(tt.loanIDs))
+				}
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Fatalf("err = %v, want %q", err, tt.wantErr)
+			}
+		})
+	}
+}
@@ -0,0 +1,758 @@
+package service
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"math/big"
+	"net"
+	"net/http"
+	"net/url"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"syscall"
+	"time"
+
+	"github.com/cina_dex_backend/internal/config"
+	"github.com/cina_dex_backend/internal/health"
+	"github.com/cina_dex_backend/internal/lifecycle"
+	"github.com/cina_dex_backend/internal/model"
+	"github.com/cina_dex_backend/internal/onchain"
+	"github.com/cina_dex_backend/internal/store"
+	"github.com/cina_dex_backend/internal/tracing"
+)
+
+const (
+	// maxWebhookThresholds bounds the LTV thresholds of one subscription.
+	maxWebhookThresholds = 10
+	// webhookDeliveryHistory is how many delivery attempts are kept per subscription.
+	webhookDeliveryHistory = 100
+	// webhookQueueSize bounds notifications waiting for a delivery worker.
+	webhookQueueSize = 1024
+	// webhookWorkers is the number of concurrent deliveries.
+	webhookWorkers = 4
+)
+
+var (
+	// ErrInvalidWebhook is returned for malformed subscription requests.
+	ErrInvalidWebhook = errors.New("invalid webhook subscription")
+	// ErrWebhookNotFound is returned for unknown subscription ids.
+	ErrWebhookNotFound = errors.New("webhook subscription not found")
+	// ErrWebhookUnauthorized is returned when the subscription secret does not match.
+	ErrWebhookUnauthorized = errors.New("invalid webhook secret")
+	// ErrWebhookLimit is returned when no more subscriptions can be registered.
+	ErrWebhookLimit = errors.New("webhook subscription limit reached")
+)
+
+// WebhookService manages loan health subscriptions and delivers signed
+// notifications when a loan's LTV crosses a subscribed threshold.
+type WebhookService interface {
+	// Subscribe registers sub (URL, LoanID or Borrower, Thresholds) for the
+	// client at clientIP and returns it with its id and signing secret.
+	Subscribe(ctx context.Context, sub *model.WebhookSubscription, clientIP string) (*model.WebhookSubscription, error)
+	// Get returns a subscription; secret must be the one returned by Subscribe.
+	Get(id, secret string) (*model.WebhookSubscription, error)
+	// Unsubscribe deletes a subscription.
+	Unsubscribe(id, secret string) error
+	// Deliveries returns recent delivery attempts, newest first.
+	Deliveries(id, secret string) ([]*model.WebhookDelivery, error)
+	// Ping queues a test notification.
+	Ping(id, secret string) (*model.WebhookEvent, error)
+
+	// Evaluate re-checks getLoanHealth of every subscribed loan and queues
+	// notifications for newly crossed thresholds.
+	Evaluate(ctx context.Context) error
+	// Run delivers queued notifications until ctx is cancelled.
+	Run(ctx context.Context)
+}
+
+type webhookService struct {
+	client      onchain.Client
+	loans       *LoanScanner
+	cache       *StateCache
+	subLog      *store.AppendLog
+	deliveryLog *store.AppendLog
+	httpClient  *http.Client
+	cfg         config.WebhooksConfig
+	queue       chan *webhookJob
+
+	// evalMu serializes Evaluate calls.
+	evalMu sync.Mutex
+	// levels[subID][loanID] is how many thresholds the loan's LTV was above
+	// at the last evaluation.
+	levels map[string]map[uint64]int
+
+	mu   sync.RWMutex
+	subs map[string]*model.WebhookSubscription
+	// clients[subID] is the IP the subscription was registered from.
+	clients    map[string]string
+	deliveries map[string][]*model.WebhookDelivery
+	// retained counts the deliveries in memory; dropped those still in the
+	// delivery log but no longer kept, which compaction removes.
+	retained, dropped int
+}
+
+// webhookRecord is a persisted subscription change.
+type webhookRecord struct {
+	Op           string                     `json:"op"` // create | delete
+	ID           string                     `json:"id"`
+	Subscription *model.WebhookSubscription `json:"subscription,omitempty"`
+	ClientIP     string                     `json:"clientIp,omitempty"`
+}
+
+// webhookJob is one notification on its way to a subscriber.
+type webhookJob struct {
+	subID   string
+	url     string
+	secret  string
+	event   *model.WebhookEvent
+	attempt int
+}
+
+// NewWebhookService opens the persisted subscriptions and delivery log under
+// cfg.DataDir.
+func NewWebhookService(cfg *config.Config, c onchain.Client, loans *LoanScanner, cache *StateCache) (WebhookService, error) {
+	subLog, err := store.OpenAppendLog(cfg.DataPath("webhooks.jsonl"))
+	if err != nil {
+		return nil, err
+	}
+	deliveryLog, err := store.OpenAppendLog(cfg.DataPath("webhook_deliveries.jsonl"))
+	if err != nil {
+		return nil, err
+	}
+
+	s := &webhookService{
+		client:      c,
+		loans:       loans,
+		cache:       cache,
+		subLog:      subLog,
+		deliveryLog: deliveryLog,
+		httpClient:  newWebhookHTTPClient(cfg.Webhooks),
+		cfg:         cfg.Webhooks,
+		queue:       make(chan *webhookJob, webhookQueueSize),
+		levels:      make(map[string]map[uint64]int),
+		subs:        make(map[string]*model.WebhookSubscription),
+		clients:     make(map[string]string),
+		deliveries:  make(map[string][]*model.WebhookDelivery),
+	}
+
+	if err := subLog.Replay(func(line []byte) error {
+		var r webhookRecord
+		if err := json.Unmarshal(line, &r); err != nil {
+			return fmt.Errorf("decode webhook record: %w", err)
+		}
+		switch r.Op {
+		case "create":
+			if r.Subscription != nil {
+				s.subs[r.Subscription.ID] = r.Subscription
+				s.clients[r.Subscription.ID] = r.ClientIP
+			}
+		case "delete":
+			delete(s.subs, r.ID)
+			delete(s.clients, r.ID)
+		}
+		return nil
+	}); err != nil {
+		return nil, err
+	}
+	if err := deliveryLog.Replay(func(line []byte) error {
+		var d model.WebhookDelivery
+		if err := json.Unmarshal(line, &d); err != nil {
+			return fmt.Errorf("decode webhook delivery: %w", err)
+		}
+		if _, ok := s.subs[d.SubscriptionID]; ok {
+			s.addDelivery(&d)
+		} else {
+			s.dropped++
+		}
+		return nil
+	}); err != nil {
+		return nil, err
+	}
+	if s.dropped > 0 {
+		if err := s.compactDeliveries(); err != nil {
+			return nil, err
+		}
+	}
+
+	return s, nil
+}
+
+// newWebhookHTTPClient does not follow redirects and, unless private targets
+// are allowed, refuses to connect to loopback / private addresses even when a
+// public hostname resolves to one.
+func newWebhookHTTPClient(cfg config.WebhooksConfig) *http.Client {
+	dialer := &net.Dialer{Timeout: cfg.Timeout}
+	if !cfg.AllowPrivate {
+		dialer.Control = func(network, address string, _ syscall.RawConn) error {
+			host, _, err := net.SplitHostPort(address)
+			if err != nil {
+				return err
+			}
+			if ip := net.ParseIP(host); ip == nil || isPrivateIP(ip) {
+				return fmt.Errorf("webhook target %s is not a public address", host)
+			}
+			return nil
+		}
+	}
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	transport.DialContext = dialer.DialContext
+
+	return &http.Client{
+		Timeout:   cfg.Timeout,
+		Transport: transport,
+		CheckRedirect: func(*http.Request, []*http.Request) error {
+			return http.ErrUseLastResponse
+		},
+	}
+}
+
+func isPrivateIP(ip net.IP) bool {
+	return ip.IsLoopback() || ip.IsPrivate() || ip.IsUnspecified() ||
+		ip.IsLinkLocalUnicast() || ip.IsLinkLocalMulticast() || ip.IsMulticast()
+}
+
+func (s *webhookService) Subscribe(ctx context.Context, req *model.WebhookSubscription, clientIP string) (*model.WebhookSubscription, error) {
+	if err := s.validateURL(req.URL); err != nil {
+		return nil, err
+	}
+
+	hasLoan, hasBorrower := req.LoanID != nil, req.Borrower != ""
+	if hasLoan == hasBorrower {
+		return nil, fmt.Errorf("%w: exactly one of loanId and borrower is required", ErrInvalidWebhook)
+	}
+	if hasBorrower && !isHexAddress(req.Borrower) {
+		return nil, fmt.Errorf("%w: invalid borrower address", ErrInvalidWebhook)
+	}
+
+	thresholds, err := normalizeThresholds(req.Thresholds)
+	if err != nil {
+		return nil, err
+	}
+
+	if hasLoan {
+		loan, err := s.client.GetLoan(ctx, *req.LoanID)
+		if err != nil {
+			return nil, fmt.Errorf("get loan: %w", err)
+		}
+		if !loan.IsActive {
+			return nil, fmt.Errorf("%w: loan %d is not active", ErrInvalidWebhook, *req.LoanID)
+		}
+	}
+
+	id, err := randomHex(8)
+	if err != nil {
+		return nil, err
+	}
+	secret, err := randomHex(32)
+	if err != nil {
+		return nil, err
+	}
+	sub := &model.WebhookSubscription{
+		ID:         "wh_" + id,
+		URL:        req.URL,
+		LoanID:     req.LoanID,
+		Borrower:   req.Borrower,
+		Thresholds: thresholds,
+		CreatedAt:  time.Now().Unix(),
+		Secret:     secret,
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if len(s.subs) >= s.cfg.MaxSubscriptions {
+		return nil, ErrWebhookLimit
+	}
+	perClient, perTarget := 0, 0
+	for id, other := range s.subs {
+		if s.clients[id] == clientIP {
+			perClient++
+		}
+		if sameWebhookTarget(other, sub) {
+			perTarget++
+		}
+	}
+	if perClient >= s.cfg.MaxPerClient {
+		return nil, fmt.Errorf("%w: at most %d per client", ErrWebhookLimit, s.cfg.MaxPerClient)
+	}
+	if perTarget >= s.cfg.MaxPerTarget {
+		return nil, fmt.Errorf("%w: at most %d per loan or borrower", ErrWebhookLimit, s.cfg.MaxPerTarget)
+	}
+	if err := s.subLog.Append(&webhookRecord{Op: "create", ID: sub.ID, Subscription: sub, ClientIP: clientIP}); err != nil {
+		return nil, fmt.Errorf("persist webhook: %w", err)
+	}
+	s.subs[sub.ID] = sub
+	s.clients[sub.ID] = clientIP
+
+	out := *sub
+	return &out, nil
+}
+
+// sameWebhookTarget reports whether a and b watch the same loan or borrower.
+func sameWebhookTarget(a, b *model.WebhookSubscription) bool {
+	if a.LoanID != nil || b.LoanID != nil {
+		return a.LoanID != nil && b.LoanID != nil && *a.LoanID == *b.LoanID
+	}
+	return strings.EqualFold(a.Borrower, b.Borrower)
+}
+
+// validateURL accepts absolute http(s) URLs. Literal private hosts are
+// rejected up front when not allowed; the dialer catches the rest.
+func (s *webhookService) validateURL(raw string) error {
+	u, err := url.Parse(raw)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		return fmt.Errorf("%w: url must be an absolute http(s) URL", ErrInvalidWebhook)
+	}
+	if s.cfg.AllowPrivate {
+		return nil
+	}
+	host := u.Hostname()
+	if ip := net.ParseIP(host); (ip != nil && isPrivateIP(ip)) || strings.EqualFold(host, "localhost") {
+		return fmt.Errorf("%w: private webhook targets are not allowed", ErrInvalidWebhook)
+	}
+	return nil
+}
+
+// normalizeThresholds sorts and de-duplicates LTV percentages.
+func normalizeThresholds(in []float64) ([]float64, error) {
+	if len(in) == 0 || len(in) > maxWebhookThresholds {
+		return nil, fmt.Errorf("%w: between 1 and %d thresholds required", ErrInvalidWebhook, maxWebhookThresholds)
+	}
+	out := make([]float64, 0, len(in))
+	for _, t := range in {
+		if !(t > 0 && t <= 200) {
+			return nil, fmt.Errorf("%w: threshold %v out of range (0, 200]", ErrInvalidWebhook, t)
+		}
+		out = append(out, t)
+	}
+	sort.Float64s(out)
+	n := 1
+	for i := 1; i < len(out); i++ {
+		if out[i] != out[n-1] {
+			out[n] = out[i]
+			n++
+		}
+	}
+	return out[:n], nil
+}
+
+// authorize returns the subscription with id when secret matches.
+func (s *webhookService) authorize(id, secret string) (*model.WebhookSubscription, error) {
+	s.mu.RLock()
+	sub, ok := s.subs[id]
+	s.mu.RUnlock()
+	if !ok {
+		return nil, ErrWebhookNotFound
+	}
+	if !hmac.Equal([]byte(sub.Secret), []byte(secret)) {
+		return nil, ErrWebhookUnauthorized
+	}
+	return sub, nil
+}
+
+func (s *webhookService) Get(id, secret string) (*model.WebhookSubscription, error) {
+	sub, err := s.authorize(id, secret)
+	if err != nil {
+		return nil, err
+	}
+	out := *sub
+	out.Secret = ""
+	return &out, nil
+}
+
+func (s *webhookService) Unsubscribe(id, secret string) error {
+	if _, err := s.authorize(id, secret); err != nil {
+		return err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if err := s.subLog.Append(&webhookRecord{Op: "delete", ID: id}); err != nil {
+		return fmt.Errorf("persist webhook: %w", err)
+	}
+	delete(s.subs, id)
+	delete(s.clients, id)
+	n := len(s.deliveries[id])
+	delete(s.deliveries, id)
+	s.retained -= n
+	s.dropped += n
+	if err := s.maybeCompactDeliveries(); err != nil {
+		slog.Error("webhooks: compact delivery log", "err", err)
+	}
+	return nil
+}
+
+func (s *webhookService) Deliveries(id, secret string) ([]*model.WebhookDelivery, error) {
+	if _, err := s.authorize(id, secret); err != nil {
+		return nil, err
+	}
+
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	list := s.deliveries[id]
+	out := make([]*model.WebhookDelivery, 0, len(list))
+	for i := len(list) - 1; i >= 0; i-- {
+		out = append(out, list[i])
+	}
+	return out, nil
+}
+
+func (s *webhookService) Ping(id, secret string) (*model.WebhookEvent, error) {
+	sub, err := s.authorize(id, secret)
+	if err != nil {
+		return nil, err
+	}
+	ev, err := newWebhookEvent(model.WebhookEventPing, sub.ID)
+	if err != nil {
+		return nil, err
+	}
+	s.enqueue(sub, ev)
+	return ev, nil
+}
+
+// Evaluate notifies a subscriber when a loan's LTV is above more thresholds
+// than at the previous evaluation. Falling back below a threshold re-arms it
+// silently. Crossing state is kept in memory, so a restart re-notifies loans
+// that are still above a threshold.
+func (s *webhookService) Evaluate(ctx context.Context) (err error) {
+	ctx, span := tracing.Start(ctx, "WebhookService.Evaluate")
+	defer func() { tracing.End(span, err) }()
+
+	s.evalMu.Lock()
+	defer s.evalMu.Unlock()
+
+	s.mu.RLock()
+	subs := make([]*model.WebhookSubscription, 0, len(s.subs))
+	for _, sub := range s.subs {
+		subs = append(subs, sub)
+	}
+	s.mu.RUnlock()
+	if len(subs) == 0 {
+		s.levels = make(map[string]map[uint64]int)
+		return nil
+	}
+
+	active, err := s.loans.ActiveLoans(ctx)
+	if err != nil {
+		return fmt.Errorf("scan loans: %w", err)
+	}
+	byID := make(map[uint64]*model.Loan, len(active))
+	for _, loan := range active {
+		byID[loan.ID] = loan
+	}
+
+	var price string
+	if s.cache != nil {
+		if info, ok := s.cache.GetPriceInfo(); ok {
+			price = info.Price
+		}
+	}
+
+	health := make(map[uint64]*model.LoanHealth)
+	levels := make(map[string]map[uint64]int, len(subs))
+	for _, sub := range subs {
+		var loans []*model.Loan
+		if sub.LoanID != nil {
+			if loan, ok := byID[*sub.LoanID]; ok {
+				loans = append(loans, loan)
+			}
+		} else {
+			for _, loan := range active {
+				if strings.EqualFold(loan.Borrower, sub.Borrower) {
+					loans = append(loans, loan)
+				}
+			}
+		}
+
+		prev := s.levels[sub.ID]
+		cur := make(map[uint64]int, len(loans))
+		for _, loan := range loans {
+			h, ok := health[loan.ID]
+			if !ok {
+				if h, err = s.client.GetLoanHealth(ctx, loan.ID); err != nil {
+					if ctx.Err() != nil {
+						return ctx.Err()
+					}
+					slog.WarnContext(ctx, "webhooks: get loan health", "loan_id", loan.ID, "err", err)
+					// keep the previous level so a failed read does not re-arm.
+					cur[loan.ID] = prev[loan.ID]
+					continue
+				}
+				health[loan.ID] = h
+			}
+			ltv, err := parseBig(h.LTV)
+			if err != nil {
+				continue
+			}
+
+			level := crossedThresholds(sub.Thresholds, ltv)
+			cur[loan.ID] = level
+			if level <= prev[loan.ID] {
+				continue
+			}
+
+			ev, err := newWebhookEvent(model.WebhookEventThreshold, sub.ID)
+			if err != nil {
+				return err
+			}
+			ev.LoanID = loan.ID
+			ev.Borrower = loan.Borrower
+			ev.Threshold = sub.Thresholds[level-1]
+			ev.LTV = ltv.String()
+			ev.LtvPercent = formatRatioPercent(ltv)
+			ev.IsLiquidatable = h.IsLiquidatable
+			ev.Price = price
+			s.enqueue(sub, ev)
+		}
+		levels[sub.ID] = cur
+	}
+	s.levels = levels
+	return nil
+}
+
+// crossedThresholds counts the ascending percentage thresholds that an
+// 18-decimal LTV is at or above.
+func crossedThresholds(thresholds []float64, ltv *big.Int) int {
+	pct, _ := new(big.Rat).SetFrac(new(big.Int).Mul(ltv, hundred), oneEther).Float64()
+	n := 0
+	for _, t := range thresholds {
+		if pct >= t {
+			n++
+		}
+	}
+	return n
+}
+
+func newWebhookEvent(typ, subID string) (*model.WebhookEvent, error) {
+	id, err := randomHex(12)
+	if err != nil {
+		return nil, err
+	}
+	return &model.WebhookEvent{
+		ID:             "evt_" + id,
+		Type:           typ,
+		SubscriptionID: subID,
+		CreatedAt:      time.Now().Unix(),
+	}, nil
+}
+
+// enqueue hands ev to the delivery workers, recording a failed delivery if
+// the queue is full.
+func (s *webhookService) enqueue(sub *model.WebhookSubscription, ev *model.WebhookEvent) {
+	job := &webhookJob{subID: sub.ID, url: sub.URL, secret: sub.Secret, event: ev, attempt: 1}
+	select {
+	case s.queue <- job:
+	default:
+		s.record(job, model.DeliveryFailed, 0, "delivery queue full", 0)
+	}
+}
+
+func (s *webhookService) Run(ctx context.Context) {
+	var wg sync.WaitGroup
+	for i := 0; i < webhookWorkers; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for {
+				select {
+				case <-ctx.Done():
+					return
+				case job := <-s.queue:
+					s.deliver(ctx, job)
+				}
+			}
+		}()
+	}
+	wg.Wait()
+}
+
+// deliver POSTs a job once and schedules a retry with exponential backoff on
+// failure, until MaxAttempts is reached.
+func (s *webhookService) deliver(ctx context.Context, job *webhookJob) {
+	// dropped subscriptions get no more attempts.
+	s.mu.RLock()
+	_, ok := s.subs[job.subID]
+	s.mu.RUnlock()
+	if !ok {
+		return
+	}
+
+	start := time.Now()
+	code, err := s.post(ctx, job)
+	elapsed := time.Since(start).Milliseconds()
+	if err == nil {
+		s.record(job, model.DeliverySucceeded, code, "", elapsed)
+		return
+	}
+	if job.attempt >= s.cfg.MaxAttempts || ctx.Err() != nil {
+		s.record(job, model.DeliveryFailed, code, err.Error(), elapsed)
+		return
+	}
+	s.record(job, model.DeliveryRetrying, code, err.Error(), elapsed)
+
+	backoff := s.cfg.RetryBackoff << (job.attempt - 1)
+	next := *job
+	next.attempt++
+	time.AfterFunc(backoff, func() {
+		select {
+		case s.queue <- &next:
+		case <-ctx.Done():
+		}
+	})
+}
+
+// post sends one signed request; any non-2xx status is an error.
+func (s *webhookService) post(ctx context.Context, job *webhookJob) (int, error) {
+	body, err := json.Marshal(job.event)
+	if err != nil {
+		return 0, fmt.Errorf("marshal event: %w", err)
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, job.url, bytes.NewReader(body))
+	if err != nil {
+		return 0, err
+	}
+	ts := time.Now().Unix()
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("X-Webhook-Id", job.event.ID)
+	req.Header.Set("X-Webhook-Event", job.event.Type)
+	req.Header.Set("X-Webhook-Timestamp", strconv.FormatInt(ts, 10))
+	req.Header.Set("X-Webhook-Signature", "sha256="+WebhookSignature(job.secret, ts, body))
+
+	resp, err := s.httpClient.Do(req)
+	if err != nil {
+		return 0, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return resp.StatusCode, fmt.Errorf("unexpected status %s", resp.Status)
+	}
+	return resp.StatusCode, nil
+}
+
+// record appends a delivery attempt to the log and the in-memory history.
+func (s *webhookService) record(job *webhookJob, status string, code int, errMsg string, durationMs int64) {
+	d := &model.WebhookDelivery{
+		SubscriptionID: job.subID,
+		EventID:        job.event.ID,
+		EventType:      job.event.Type,
+		Attempt:        job.attempt,
+		Status:         status,
+		StatusCode:     code,
+		Error:          errMsg,
+		DurationMs:     durationMs,
+		At:             time.Now().Unix(),
+	}
+
+	// append under mu so compaction cannot drop a delivery between the log
+	// and memory.
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if err := s.deliveryLog.Append(d); err != nil {
+		slog.Error("webhooks: persist delivery", "err", err)
+	}
+	if _, ok := s.subs[job.subID]; !ok {
+		s.dropped++
+	} else {
+		s.addDelivery(d)
+	}
+	if err := s.maybeCompactDeliveries(); err != nil {
+		slog.Error("webhooks: compact delivery log", "err", err)
+	}
+}
+
+// addDelivery keeps the last webhookDeliveryHistory attempts; callers hold mu.
+func (s *webhookService) addDelivery(d *model.WebhookDelivery) {
+	list := append(s.deliveries[d.SubscriptionID], d)
+	s.retained++
+	if n := len(list) - webhookDeliveryHistory; n > 0 {
+		list = append([]*model.WebhookDelivery(nil), list[n:]...)
+		s.retained -= n
+		s.dropped += n
+	}
+	s.deliveries[d.SubscriptionID] = list
+}
+
+// maybeCompactDeliveries compacts the delivery log once dropped deliveries
+// outnumber retained ones, so it stays within roughly twice the history.
+// Callers hold mu.
+func (s *webhookService) maybeCompactDeliveries() error {
+	if s.dropped <= s.retained {
+		return nil
+	}
+	return s.compactDeliveries()
+}
+
+// compactDeliveries rewrites the delivery log with the retained deliveries,
+// oldest first. Callers hold mu (or have exclusive access during construction).
+func (s *webhookService) compactDeliveries() error {
+	all := make([]*model.WebhookDelivery, 0, s.retained)
+	for _, list := range s.deliveries {
+		all = append(all, list...)
+	}
+	sort.SliceStable(all, func(i, j int) bool { return all[i].At < all[j].At })
+	records := make([]interface{}, len(all))
+	for i, d := range all {
+		records[i] = d
+	}
+	if err := s.deliveryLog.Rewrite(records); err != nil {
+		return fmt.Errorf("compact webhook deliveries: %w", err)
+	}
+	s.dropped = 0
+	return nil
+}
+
+// WebhookSignature returns hex(HMAC-SHA256(secret, "<timestamp>.<body>")),
+// the value of the X-Webhook-Signature header after "sha256=".
+func WebhookSignature(secret string, timestamp int64, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(strconv.FormatInt(timestamp, 10)))
+	mac.Write([]byte("."))
+	mac.Write(body)
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+func randomHex(n int) (string, error) {
+	b := make([]byte, n)
+	if _, err := rand.Read(b); err != nil {
+		return "", fmt.Errorf("generate random id: %w", err)
+	}
+	return hex.EncodeToString(b), nil
+}
+
+// StartWebhooks launches the delivery workers and re-evaluates subscriptions
+// whenever the cache receives a new price.
+func StartWebhooks(ctx context.Context, workers *lifecycle.Workers, svc WebhookService, cache *StateCache) {
+	if svc == nil || cache == nil {
+		return
+	}
+	// evaluations follow cache updates, so only failures are reported.
+	health.Register("webhook_evaluator", 0, false)
+	workers.Go("webhook delivery", func() { svc.Run(ctx) })
+
+	updates, unsubscribe := cache.Subscribe()
+	workers.Go("webhook evaluator", func() {
+		defer unsubscribe()
+		for {
+			select {
+			case <-ctx.Done():
+				slog.Info("webhook evaluator stopped: context cancelled")
+				return
+			case <-updates:
+				err := svc.Evaluate(ctx)
+				jobDone("webhook_evaluator", err)
+				if err != nil {
+					slog.ErrorContext(ctx, "webhook evaluator: evaluate", "err", err)
+				}
+			}
+		}
+	})
+}
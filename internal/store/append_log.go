@@ -0,0 +1,134 @@
+package store
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+)
+
+// AppendLog persists append-only records as JSON lines on disk.
+// A nil *AppendLog is valid and keeps nothing, so callers can run memory-only
+// when no data directory is configured.
+type AppendLog struct {
+	mu   sync.Mutex
+	path string
+	f    *os.File
+}
+
+// OpenAppendLog opens (or creates) the JSON-lines file at path. An empty path
+// returns a nil log.
+func OpenAppendLog(path string) (*AppendLog, error) {
+	if path == "" {
+		return nil, nil
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return nil, fmt.Errorf("create data dir: %w", err)
+	}
+	f, err := os.OpenFile(path, os.O_CREATE|os.O_RDWR|os.O_APPEND, 0o644)
+	if err != nil {
+		return nil, fmt.Errorf("open %s: %w", path, err)
+	}
+	return &AppendLog{path: path, f: f}, nil
+}
+
+// Append writes v as a single JSON line.
+func (l *AppendLog) Append(v interface{}) error {
+	if l == nil {
+		return nil
+	}
+	bz, err := json.Marshal(v)
+	if err != nil {
+		return fmt.Errorf("marshal record: %w", err)
+	}
+	bz = append(bz, '\n')
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if _, err := l.f.Write(bz); err != nil {
+		return fmt.Errorf("append %s: %w", l.path, err)
+	}
+	return nil
+}
+
+// Replay calls fn for every stored line, oldest first.
+func (l *AppendLog) Replay(fn func(line []byte) error) error {
+	if l == nil {
+		return nil
+	}
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	f, err := os.Open(l.path)
+	if err != nil {
+		return fmt.Errorf("open %s: %w", l.path, err)
+	}
+	defer f.Close()
+
+	sc := bufio.NewScanner(f)
+	sc.Buffer(make([]byte, 64*1024), 4*1024*1024)
+	for sc.Scan() {
+		if len(sc.Bytes()) == 0 {
+			continue
+		}
+		if err := fn(sc.Bytes()); err != nil {
+			return err
+		}
+	}
+	return sc.Err()
+}
+
+// Rewrite atomically replaces the file contents with records, one JSON line
+// each. It is used to compact logs after old records expire.
+func (l *AppendLog) Rewrite(records []interface{}) error {
+	if l == nil {
+		return nil
+	}
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	tmp := l.path + ".tmp"
+	f, err := os.Create(tmp)
+	if err != nil {
+		return fmt.Errorf("create %s: %w", tmp, err)
+	}
+	w := bufio.NewWriter(f)
+	enc := json.NewEncoder(w)
+	for _, r := range records {
+		if err := enc.Encode(r); err != nil {
+			f.Close()
+			return fmt.Errorf("marshal record: %w", err)
+		}
+	}
+	if err := w.Flush(); err != nil {
+		f.Close()
+		return fmt.Errorf("write %s: %w", tmp, err)
+	}
+	if err := f.Close(); err != nil {
+		return fmt.Errorf("close %s: %w", tmp, err)
+	}
+	if err := os.Rename(tmp, l.path); err != nil {
+		return fmt.Errorf("replace %s: %w", l.path, err)
+	}
+
+	// reopen so further appends go to the new file
+	nf, err := os.OpenFile(l.path, os.O_CREATE|os.O_RDWR|os.O_APPEND, 0o644)
+	if err != nil {
+		return fmt.Errorf("reopen %s: %w", l.path, err)
+	}
+	l.f.Close()
+	l.f = nf
+	return nil
+}
+
+// Close closes the underlying file.
+func (l *AppendLog) Close() error {
+	if l == nil {
+		return nil
+	}
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return l.f.Close()
+}
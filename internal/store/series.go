@@ -0,0 +1,158 @@
+package store
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+)
+
+// Point is a single timestamped value of a Series.
+type Point[T any] struct {
+	// Time is a unix timestamp in seconds.
+	Time  int64 `json:"t"`
+	Value T     `json:"v"`
+}
+
+// Series is an in-memory, time-ordered series of points with a retention
+// period, optionally persisted to an AppendLog so it survives restarts.
+type Series[T any] struct {
+	mu        sync.RWMutex
+	points    []Point[T]
+	retention time.Duration
+	log       *AppendLog
+	// expired counts points pruned from memory but still present in the log.
+	expired int
+}
+
+// NewSeries loads previously persisted points from log (which may be nil)
+// and drops those older than retention. A zero retention keeps everything.
+func NewSeries[T any](log *AppendLog, retention time.Duration) (*Series[T], error) {
+	s := &Series[T]{retention: retention, log: log}
+
+	if err := log.Replay(func(line []byte) error {
+		var p Point[T]
+		if err := json.Unmarshal(line, &p); err != nil {
+			return fmt.Errorf("decode series point: %w", err)
+		}
+		s.points = append(s.points, p)
+		return nil
+	}); err != nil {
+		return nil, err
+	}
+
+	sort.SliceStable(s.points, func(i, j int) bool { return s.points[i].Time < s.points[j].Time })
+	before := len(s.points)
+	s.prune(time.Now())
+	if len(s.points) != before {
+		if err := s.compact(); err != nil {
+			return nil, err
+		}
+	}
+	return s, nil
+}
+
+// Add records v at time t. Points normally arrive in order; late points are
+// inserted at their position.
+func (s *Series[T]) Add(t time.Time, v T) error {
+	p := Point[T]{Time: t.Unix(), Value: v}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	i := len(s.points)
+	if i > 0 && s.points[i-1].Time > p.Time {
+		i = sort.Search(len(s.points), func(k int) bool { return s.points[k].Time > p.Time })
+	}
+	s.points = append(s.points, Point[T]{})
+	copy(s.points[i+1:], s.points[i:])
+	s.points[i] = p
+
+	s.prune(time.Now())
+	if err := s.log.Append(p); err != nil {
+		return err
+	}
+	// Compact once expired points outnumber live ones, so the log stays
+	// within roughly twice the retention.
+	if s.expired > len(s.points) {
+		return s.compact()
+	}
+	return nil
+}
+
+// Range returns the points with from <= time < to, oldest first.
+func (s *Series[T]) Range(from, to time.Time) []Point[T] {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	lo := sort.Search(len(s.points), func(i int) bool { return s.points[i].Time >= from.Unix() })
+	hi := sort.Search(len(s.points), func(i int) bool { return s.points[i].Time >= to.Unix() })
+	return append([]Point[T](nil), s.points[lo:hi]...)
+}
+
+// At returns the latest point at or before t.
+func (s *Series[T]) At(t time.Time) (Point[T], bool) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	i := sort.Search(len(s.points), func(i int) bool { return s.points[i].Time > t.Unix() })
+	if i == 0 {
+		return Point[T]{}, false
+	}
+	return s.points[i-1], true
+}
+
+// Last returns the most recent point.
+func (s *Series[T]) Last() (Point[T], bool) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	if len(s.points) == 0 {
+		return Point[T]{}, false
+	}
+	return s.points[len(s.points)-1], true
+}
+
+// First returns the oldest retained point.
+func (s *Series[T]) First() (Point[T], bool) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	if len(s.points) == 0 {
+		return Point[T]{}, false
+	}
+	return s.points[0], true
+}
+
+// Len returns the number of retained points.
+func (s *Series[T]) Len() int {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return len(s.points)
+}
+
+// prune drops points older than the retention. Callers must hold mu.
+func (s *Series[T]) prune(now time.Time) {
+	if s.retention <= 0 {
+		return
+	}
+	cutoff := now.Add(-s.retention).Unix()
+	i := sort.Search(len(s.points), func(i int) bool { return s.points[i].Time >= cutoff })
+	if i > 0 {
+		s.points = append(s.points[:0], s.points[i:]...)
+		s.expired += i
+	}
+}
+
+// compact rewrites the log with the retained points. Callers must hold mu
+// (or have exclusive access during construction).
+func (s *Series[T]) compact() error {
+	records := make([]interface{}, len(s.points))
+	for i := range s.points {
+		records[i] = s.points[i]
+	}
+	if err := s.log.Rewrite(records); err != nil {
+		return err
+	}
+	s.expired = 0
+	return nil
+}
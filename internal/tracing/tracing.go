@@ -0,0 +1,114 @@
+// Package tracing sets up OpenTelemetry tracing and offers helpers to start
+// spans with the attributes used across handlers, services and RPC calls.
+package tracing
+
+import (
+	"context"
+	"fmt"
+	"os"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/exporters/otlp/otlptrace/otlptracehttp"
+	"go.opentelemetry.io/otel/exporters/stdout/stdouttrace"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/sdk/resource"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	semconv "go.opentelemetry.io/otel/semconv/v1.26.0"
+	"go.opentelemetry.io/otel/trace"
+
+	"github.com/cina_dex_backend/internal/config"
+)
+
+const instrumentation = "github.com/cina_dex_backend"
+
+// Span attribute keys.
+const (
+	AddressKey   = attribute.Key("cina.address")
+	LoanIDKey    = attribute.Key("cina.loan_id")
+	LoanCountKey = attribute.Key("cina.loan_count")
+	CacheKey     = attribute.Key("cache.key")
+	CacheResult  = attribute.Key("cache.result")
+	RPCMethod    = attribute.Key("rpc.method")
+	FunctionKey  = attribute.Key("eth.function")
+	SelectorKey  = attribute.Key("eth.selector")
+	BlockKey     = attribute.Key("eth.block")
+)
+
+// Init installs the global tracer provider configured by cfg and returns a
+// function that flushes and stops it. With no exporter configured the
+// global no-op provider stays in place and spans cost almost nothing.
+func Init(ctx context.Context, cfg config.TracingConfig) (func(context.Context) error, error) {
+	// accept W3C traceparent / baggage from callers either way.
+	otel.SetTextMapPropagator(propagation.NewCompositeTextMapPropagator(propagation.TraceContext{}, propagation.Baggage{}))
+
+	var spanProcessor sdktrace.SpanProcessor
+	switch cfg.Exporter {
+	case config.TracingNone:
+		return func(context.Context) error { return nil }, nil
+	case config.TracingStdout:
+		exp, err := stdouttrace.New(stdouttrace.WithWriter(os.Stdout), stdouttrace.WithPrettyPrint())
+		if err != nil {
+			return nil, fmt.Errorf("create stdout exporter: %w", err)
+		}
+		// write spans as they end so they show up without a flush.
+		spanProcessor = sdktrace.NewSimpleSpanProcessor(exp)
+	case config.TracingOTLP:
+		// endpoint, headers and TLS come from the standard
+		// OTEL_EXPORTER_OTLP_* environment variables.
+		exp, err := otlptracehttp.New(ctx)
+		if err != nil {
+			return nil, fmt.Errorf("create otlp exporter: %w", err)
+		}
+		spanProcessor = sdktrace.NewBatchSpanProcessor(exp)
+	default:
+		return nil, fmt.Errorf("unsupported tracing exporter: %s", cfg.Exporter)
+	}
+
+	res, err := resource.New(ctx,
+		resource.WithAttributes(semconv.ServiceName(cfg.ServiceName)),
+		resource.WithFromEnv(),
+		resource.WithTelemetrySDK(),
+	)
+	if err != nil {
+		return nil, fmt.Errorf("create trace resource: %w", err)
+	}
+
+	tp := sdktrace.NewTracerProvider(
+		sdktrace.WithSpanProcessor(spanProcessor),
+		sdktrace.WithResource(res),
+		sdktrace.WithSampler(sdktrace.ParentBased(sdktrace.TraceIDRatioBased(cfg.SampleRatio))),
+	)
+	otel.SetTracerProvider(tp)
+	return tp.Shutdown, nil
+}
+
+// Start starts a span named name as a child of the span in ctx.
+func Start(ctx context.Context, name string, attrs ...attribute.KeyValue) (context.Context, trace.Span) {
+	return otel.Tracer(instrumentation).Start(ctx, name, trace.WithAttributes(attrs...))
+}
+
+// End records err, if any, on span and ends it.
+func End(span trace.Span, err error) {
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+	}
+	span.End()
+}
+
+// Address is the span attribute for a user address.
+func Address(addr string) attribute.KeyValue {
+	return AddressKey.String(addr)
+}
+
+// LoanID is the span attribute for a loan id.
+func LoanID(id uint64) attribute.KeyValue {
+	return LoanIDKey.Int64(int64(id))
+}
+
+// LoanCount is the span attribute for the number of loans in a request.
+func LoanCount(n int) attribute.KeyValue {
+	return LoanCountKey.Int(n)
+}
@@ -0,0 +1,55 @@
+// Package requestid carries the per-request ID through contexts so logs,
+// spans and error responses of one request can be correlated.
+package requestid
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+)
+
+// Header is the request and response header carrying the ID.
+const Header = "X-Request-ID"
+
+// maxLen bounds IDs accepted from clients.
+const maxLen = 64
+
+type ctxKey struct{}
+
+// New returns a random 32-character hex ID.
+func New() string {
+	var b [16]byte
+	rand.Read(b[:])
+	return hex.EncodeToString(b[:])
+}
+
+// Valid reports whether an ID supplied by a client may be reused: at most 64
+// letters, digits, '-', '_', '.' or ':'.
+func Valid(id string) bool {
+	if id == "" || len(id) > maxLen {
+		return false
+	}
+	for _, r := range id {
+		switch {
+		case r >= 'a' && r <= 'z', r >= 'A' && r <= 'Z', r >= '0' && r <= '9':
+		case r == '-', r == '_', r == '.', r == ':':
+		default:
+			return false
+		}
+	}
+	return true
+}
+
+// NewContext returns a copy of ctx carrying id.
+func NewContext(ctx context.Context, id string) context.Context {
+	return context.WithValue(ctx, ctxKey{}, id)
+}
+
+// FromContext returns the ID carried by ctx, or "".
+func FromContext(ctx context.Context) string {
+	if ctx == nil {
+		return ""
+	}
+	id, _ := ctx.Value(ctxKey{}).(string)
+	return id
+}
@@ -1,10 +1,18 @@
 package response
 
+import (
+	"context"
+
+	"github.com/cina_dex_backend/pkg/requestid"
+)
+
 // Response defines a standard API response envelope.
 type Response struct {
 	Code    int         `json:"code"`
 	Message string      `json:"message"`
 	Data    interface{} `json:"data,omitempty"`
+	// RequestID is set on errors so users can quote it in reports.
+	RequestID string `json:"requestId,omitempty"`
 }
 
 // Success wraps a successful response with data.
@@ -16,10 +24,23 @@ func Success(data interface{}) Response {
 	}
 }
 
-// Error wraps an error response with a custom code and message.
-func Error(code int, msg string) Response {
+// Error wraps an error response with a custom code and message, tagged with
+// the request ID carried by ctx.
+func Error(ctx context.Context, code int, msg string) Response {
+	return Response{
+		Code:      code,
+		Message:   msg,
+		RequestID: requestid.FromContext(ctx),
+	}
+}
+
+// ErrorWithData wraps an error response that still carries data, e.g. the
+// failing checks of a readiness probe.
+func ErrorWithData(ctx context.Context, code int, msg string, data interface{}) Response {
 	return Response{
-		Code:    code,
-		Message: msg,
+		Code:      code,
+		Message:   msg,
+		Data:      data,
+		RequestID: requestid.FromContext(ctx),
 	}
 }